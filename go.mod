@@ -9,6 +9,7 @@ require (
 	github.com/charmbracelet/lipgloss v0.6.0
 	github.com/lrstanley/bubblezone v0.0.0-20221217035003-70987ad7d934
 	github.com/mbndr/figlet4go v0.0.0-20190224160619-d6cef5b186ea
+	gopkg.in/yaml.v3 v3.0.1
 	gorm.io/driver/sqlite v1.4.3
 	gorm.io/gorm v1.24.2
 )
@@ -32,5 +33,4 @@ require (
 	golang.org/x/sys v0.3.0 // indirect
 	golang.org/x/term v0.3.0 // indirect
 	golang.org/x/text v0.5.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,208 @@
+// Package cli implements goalie's subcommands, which read and modify the same
+// data as the TUI for use from scripts and other programs.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benhsm/goalie/internal/config"
+	"github.com/benhsm/goalie/internal/data"
+)
+
+// dateLayout is the format accepted and printed for dates
+const dateLayout = "2006-01-02"
+
+type command struct {
+	name  string
+	usage string
+	run   func(c *cli, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"add", "add [--date YYYY-MM-DD] [--json] <intention>...", runAdd},
+		{"list", "list [--date YYYY-MM-DD] [--json]", runList},
+		{"done", "done [--date YYYY-MM-DD] [--json] <n>", runDone},
+		{"pomo", "pomo [--date YYYY-MM-DD] [--count N] [--json] <n>", runPomo},
+		{"recur", "recur add [--date YYYY-MM-DD] <schedule> <intention>\n  goalie recur list [--json]\n  goalie recur rm <n>", runRecur},
+		{"timezone", "timezone [NAME]", runTimezone},
+		{"export", "export [--format json | markdown | todotxt | ics] [--from YYYY-MM-DD] [--to YYYY-MM-DD]", runExport},
+		{"import", "import [--format json | todotxt | complice] [--date YYYY-MM-DD] [--dry-run] <file | ->", runImport},
+		{"help", "help", runHelp},
+	}
+}
+
+type cli struct {
+	store  data.Store
+	config config.Config
+	out    io.Writer
+}
+
+// Run runs the subcommand named by the first of args against store, writing
+// its output to out.
+func Run(store data.Store, cfg config.Config, args []string, out io.Writer) error {
+	c := &cli{store: store, config: cfg, out: out}
+	if len(args) == 0 {
+		return runHelp(c, nil)
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			err := cmd.run(c, args[1:])
+			if errors.Is(err, flag.ErrHelp) {
+				// the flag package has already printed the usage
+				return nil
+			}
+			return err
+		}
+	}
+	return fmt.Errorf("unknown command %q, see 'goalie help'", args[0])
+}
+
+func runHelp(c *cli, args []string) error {
+	Usage(c.out)
+	return nil
+}
+
+// Usage prints a summary of goalie's subcommands to w
+func Usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  goalie [--db PATH | --profile NAME] [command]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Without a command, goalie starts the TUI. Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintln(w, "  goalie "+cmd.usage)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "The database is chosen by --db, then --profile, then $"+data.DBEnv+".")
+}
+
+// parse parses flags and positional arguments in any order, returning the
+// positional arguments.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// dateFlag registers the --date flag, which defaults to the current day
+func (c *cli) dateFlag(fs *flag.FlagSet) *string {
+	return fs.String("date", c.config.Day.CurrentDay().Format(dateLayout), "the day to act on")
+}
+
+func parseDate(s string) (time.Time, error) {
+	date, err := time.ParseInLocation(dateLayout, s, time.UTC)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return date, nil
+}
+
+// whys returns the active goals, whose codes intentions' prefixes are read
+// with, ordered as in the TUI
+func (c *cli) whys() ([]data.Why, error) {
+	whys, err := c.store.GetWhys(data.Active)
+	sort.Slice(whys, func(i, j int) bool {
+		return whys[i].Number < whys[j].Number
+	})
+	return whys, err
+}
+
+// intentions returns the day's intentions, ordered by position
+func (c *cli) intentions(day time.Time) ([]data.Intention, error) {
+	intentions, err := c.store.GetDaysIntentions(day)
+	sort.Slice(intentions, func(i, j int) bool {
+		return intentions[i].Position < intentions[j].Position
+	})
+	return intentions, err
+}
+
+// intentionJSON is the form in which intentions are printed with --json
+type intentionJSON struct {
+	Number     int      `json:"number"`
+	Date       string   `json:"date"`
+	Content    string   `json:"content"`
+	Done       bool     `json:"done"`
+	Cancelled  bool     `json:"cancelled"`
+	Unintended bool     `json:"unintended"`
+	Outcome    bool     `json:"outcome"`
+	Planned    bool     `json:"planned"`
+	Pomos      int      `json:"pomos"`
+	Goals      []string `json:"goals"`
+}
+
+func toJSON(n int, i data.Intention) intentionJSON {
+	goals := []string{}
+	for _, why := range i.Whys {
+		goals = append(goals, why.Name)
+	}
+	return intentionJSON{
+		Number:     n,
+		Date:       i.Date.Format(dateLayout),
+		Content:    i.Content,
+		Done:       i.Done,
+		Cancelled:  i.Cancelled,
+		Unintended: i.Unintended,
+		Outcome:    i.Outcome,
+		Planned:    i.Planned,
+		Pomos:      i.Pomos,
+		Goals:      goals,
+	}
+}
+
+// printIntentions prints intentions numbered from first, either as a
+// human-readable list or as a JSON array
+func (c *cli) printIntentions(intentions []data.Intention, first int, asJSON bool) error {
+	if asJSON {
+		result := []intentionJSON{}
+		for i, intention := range intentions {
+			result = append(result, toJSON(first+i, intention))
+		}
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	for i, intention := range intentions {
+		fmt.Fprintln(c.out, formatIntention(first+i, intention))
+	}
+	return nil
+}
+
+func formatIntention(n int, i data.Intention) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%2d. ", n)
+	switch {
+	case i.Cancelled:
+		b.WriteString("[-] ")
+	case i.Done:
+		b.WriteString("[x] ")
+	default:
+		b.WriteString("[ ] ")
+	}
+	b.WriteString(i.Content)
+	if i.Pomos > 0 {
+		fmt.Fprintf(&b, " (%d pomos)", i.Pomos)
+	}
+	if i.Planned {
+		b.WriteString(" (planned)")
+	}
+	return b.String()
+}
+
+var errNoNumber = errors.New("expected the number of an intention, as shown by 'goalie list'")
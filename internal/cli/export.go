@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/benhsm/goalie/internal/complice"
+	"github.com/benhsm/goalie/internal/data"
+)
+
+func runExport(c *cli, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "json", "the format to export in: json, markdown, todotxt or ics")
+	today := c.config.Day.CurrentDay()
+	from := fs.String("from", today.AddDate(0, 0, -6).Format(dateLayout), "the first day to export, for formats other than json")
+	to := fs.String("to", today.Format(dateLayout), "the last day to export, for formats other than json")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected argument %q", args[0])
+	}
+	start, err := parseDate(*from)
+	if err != nil {
+		return err
+	}
+	end, err := parseDate(*to)
+	if err != nil {
+		return err
+	}
+	if end.Before(start) {
+		return fmt.Errorf("--to %s is before --from %s", *to, *from)
+	}
+
+	switch *format {
+	case "json":
+		doc, err := c.store.Export()
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case "markdown":
+		return c.writeMarkdown(c.out, start, end)
+	case "todotxt":
+		return c.writeTodoTxt(c.out, start, end)
+	case "ics":
+		return c.writeICS(c.out, start, end)
+	}
+	return fmt.Errorf("unknown export format %q", *format)
+}
+
+func runImport(c *cli, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "json", "the format of the file to import: json, todotxt or complice")
+	date := fs.String("date", c.config.Day.CurrentDay().Format(dateLayout), "the day to add todo.txt tasks without a creation or completion date to")
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without saving anything")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("expected the file to import, or - for standard input")
+	}
+	contents, err := readInput(args[0])
+	if err != nil {
+		return err
+	}
+
+	var stats data.ImportStats
+	switch *format {
+	case "json":
+		var doc data.Export
+		if err := json.Unmarshal(contents, &doc); err != nil {
+			return fmt.Errorf("invalid export %s: %w", args[0], err)
+		}
+		stats, err = c.store.Import(doc, *dryRun)
+	case "todotxt":
+		day, dateErr := parseDate(*date)
+		if dateErr != nil {
+			return dateErr
+		}
+		stats, err = c.importTodoTxt(string(contents), day, *dryRun)
+	case "complice":
+		doc, parseErr := complice.Parse(contents)
+		if parseErr != nil {
+			return fmt.Errorf("invalid Complice export %s: %w", args[0], parseErr)
+		}
+		stats, err = c.store.Import(doc, *dryRun)
+	default:
+		return fmt.Errorf("unknown import format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	c.printImportStats(stats, *dryRun)
+	return nil
+}
+
+// readInput reads the named file, or standard input if name is "-"
+func readInput(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(name)
+}
+
+func (c *cli) printImportStats(stats data.ImportStats, dryRun bool) {
+	kinds := make(map[string]bool)
+	for k := range stats.Added {
+		kinds[k] = true
+	}
+	for k := range stats.Skipped {
+		kinds[k] = true
+	}
+	if len(kinds) == 0 {
+		fmt.Fprintln(c.out, "nothing to import")
+		return
+	}
+	var sorted []string
+	for k := range kinds {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	added := "added"
+	if dryRun {
+		added = "to add"
+		fmt.Fprintln(c.out, "dry run, nothing was saved")
+	}
+	for _, k := range sorted {
+		fmt.Fprintf(c.out, "%-12s %d %s, %d already present\n", k+":", stats.Added[k], added, stats.Skipped[k])
+	}
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benhsm/goalie/internal/config"
+	"github.com/benhsm/goalie/internal/data"
+)
+
+// newTestStore opens a store in a temporary directory holding a goal with an
+// intention on 2026-10-15, done, and one planned for 2026-10-16
+func newTestStore(t *testing.T) data.Store {
+	t.Helper()
+	store, err := data.NewStore(filepath.Join(t.TempDir(), "goalie.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	whys := []data.Why{{Name: "Work", Code: "w"}}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	intentions := []data.Intention{
+		{Date: day, Content: "w) write the report", Done: true, Whys: []*data.Why{&whys[0]}},
+		{Date: day.AddDate(0, 0, 1), Content: "w) send the report", Planned: true, Whys: []*data.Why{&whys[0]}},
+	}
+	if err := store.UpsertIntentions(intentions); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestExportFormats(t *testing.T) {
+	store := newTestStore(t)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"markdown", "write the report"},
+		{"todotxt", "write the report"},
+		{"ics", "SUMMARY:w) write the report"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		args := []string{"export", "--format", tt.format, "--from", "2026-10-15", "--to", "2026-10-16"}
+		if err := Run(store, config.Default(), args, &out); err != nil {
+			t.Errorf("%s: %v", tt.format, err)
+			continue
+		}
+		if !strings.Contains(out.String(), tt.want) {
+			t.Errorf("%s export doesn't contain %q:\n%s", tt.format, tt.want, out.String())
+		}
+		// intentions planned ahead aren't the day's own until confirmed
+		if strings.Contains(out.String(), "send the report") {
+			t.Errorf("%s export contains a planned intention:\n%s", tt.format, out.String())
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// writeICS writes the intentions of the days from start up to and including
+// end as iCalendar to-dos, and their day reviews as journal entries
+func (c *cli) writeICS(w io.Writer, start, end time.Time) error {
+	intentions, err := c.store.GetIntentionsBetween(start, end)
+	if err != nil {
+		return err
+	}
+	days, err := c.store.GetDayReviewsBetween(start, end)
+	if err != nil {
+		return err
+	}
+
+	ics := icsWriter{w: bufio.NewWriter(w)}
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	ics.line("BEGIN:VCALENDAR")
+	ics.line("VERSION:2.0")
+	ics.line("PRODID:-//goalie//goalie//EN")
+
+	for _, i := range intentions {
+		status := "NEEDS-ACTION"
+		switch {
+		case i.Cancelled:
+			status = "CANCELLED"
+		case i.Done:
+			status = "COMPLETED"
+		}
+		var goals []string
+		for _, why := range i.Whys {
+			goals = append(goals, icsText(why.Name))
+		}
+
+		ics.line("BEGIN:VTODO")
+		ics.line(fmt.Sprintf("UID:intention-%d@goalie", i.ID))
+		ics.line("DTSTAMP:" + stamp)
+		ics.line("DTSTART;VALUE=DATE:" + i.Date.Format("20060102"))
+		ics.line("DUE;VALUE=DATE:" + i.Date.AddDate(0, 0, 1).Format("20060102"))
+		ics.line("SUMMARY:" + icsText(i.Content))
+		ics.line("STATUS:" + status)
+		if len(goals) > 0 {
+			ics.line("CATEGORIES:" + strings.Join(goals, ","))
+		}
+		if i.Pomos > 0 {
+			ics.line("DESCRIPTION:" + pomodoros(i.Pomos))
+		}
+		ics.line("END:VTODO")
+	}
+
+	for _, day := range days {
+		goal := "Other"
+		summary := goal
+		if day.WhyID != 0 {
+			goal = day.Why.Name
+			if day.Enough {
+				summary = goal + ": did enough"
+			} else {
+				summary = goal + ": did not do enough"
+			}
+		}
+
+		ics.line("BEGIN:VJOURNAL")
+		ics.line(fmt.Sprintf("UID:day-%s-%d@goalie", day.Date.Format("20060102"), day.WhyID))
+		ics.line("DTSTAMP:" + stamp)
+		ics.line("DTSTART;VALUE=DATE:" + day.Date.Format("20060102"))
+		ics.line("SUMMARY:" + icsText(summary))
+		ics.line("CATEGORIES:" + icsText(goal))
+		if day.Reflection != "" {
+			ics.line("DESCRIPTION:" + icsText(day.Reflection))
+		}
+		ics.line("END:VJOURNAL")
+	}
+
+	ics.line("END:VCALENDAR")
+	return ics.flush()
+}
+
+// icsWriter writes content lines, folding them to the 75 octet limit of RFC
+// 5545 and ending them with CRLF
+type icsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (i *icsWriter) line(s string) {
+	if i.err != nil {
+		return
+	}
+	limit := 75
+	for len(s) > limit {
+		// don't split a multi-byte character across lines
+		n := limit
+		for !utf8.RuneStart(s[n]) {
+			n--
+		}
+		_, i.err = i.w.WriteString(s[:n] + "\r\n ")
+		s = s[n:]
+		// continuation lines begin with a space, so they hold one octet less
+		limit = 74
+	}
+	_, i.err = i.w.WriteString(s + "\r\n")
+}
+
+func (i *icsWriter) flush() error {
+	if i.err != nil {
+		return i.err
+	}
+	return i.w.Flush()
+}
+
+// icsText escapes s for use as an iCalendar TEXT value
+func icsText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/benhsm/goalie/internal/data"
+)
+
+func TestICSText(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{"plain", "plain"},
+		{`a\b`, `a\\b`},
+		{"one; two, three", `one\; two\, three`},
+		{"line\r\nbreaks\nhere", `line\nbreaks\nhere`},
+	}
+	for _, tt := range tests {
+		if got := icsText(tt.s); got != tt.want {
+			t.Errorf("icsText(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestICSFolding(t *testing.T) {
+	for _, s := range []string{
+		"SUMMARY:short",
+		"DESCRIPTION:" + strings.Repeat("long ", 40),
+		// multi-byte characters aren't split across lines
+		"SUMMARY:" + strings.Repeat("é", 100),
+		"SUMMARY:" + strings.Repeat("🍅", 50),
+	} {
+		var b bytes.Buffer
+		ics := icsWriter{w: bufio.NewWriter(&b)}
+		ics.line(s)
+		if err := ics.flush(); err != nil {
+			t.Fatal(err)
+		}
+		out := b.String()
+		if !strings.HasSuffix(out, "\r\n") {
+			t.Errorf("%q doesn't end with CRLF", out)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+			if len(line) > 75 || !utf8.ValidString(line) {
+				t.Errorf("folded line %q is longer than 75 octets or splits a character", line)
+			}
+		}
+		if got := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); got != s {
+			t.Errorf("unfolding gave %q, want %q", got, s)
+		}
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	store := newTestStore(t)
+	whys, err := store.GetWhys(data.All)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	intentions := []data.Intention{
+		{Date: day, Content: "w) call, then email", Cancelled: true, Position: 1, Pomos: 2, Whys: []*data.Why{&whys[0]}},
+		{Date: day, Content: "&) rest", Position: 2},
+	}
+	if err := store.UpsertIntentions(intentions); err != nil {
+		t.Fatal(err)
+	}
+	days := []data.Day{
+		{Date: day, WhyID: whys[0].ID, Reflection: "slow; but steady"},
+		{Date: day, Reflection: "tired"},
+	}
+	if err := store.UpsertDayReview(days); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	c := &cli{store: store, out: &out}
+	if err := c.writeICS(&out, day, day); err != nil {
+		t.Fatal(err)
+	}
+	ics := strings.ReplaceAll(out.String(), "\r\n", "\n")
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\nVERSION:2.0\n",
+		"SUMMARY:w) write the report\nSTATUS:COMPLETED\nCATEGORIES:Work\n",
+		"DTSTART;VALUE=DATE:20261015\nDUE;VALUE=DATE:20261016\nSUMMARY:w) call\\, then email\nSTATUS:CANCELLED\nCATEGORIES:Work\nDESCRIPTION:2 pomodoros\n",
+		"SUMMARY:&) rest\nSTATUS:NEEDS-ACTION\nEND:VTODO\n",
+		"SUMMARY:Work: did not do enough\nCATEGORIES:Work\nDESCRIPTION:slow\\; but steady\n",
+		"UID:day-20261015-0@goalie\n",
+		"SUMMARY:Other\nCATEGORIES:Other\nDESCRIPTION:tired\n",
+		"END:VCALENDAR\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("export doesn't contain %q:\n%s", want, ics)
+		}
+	}
+	if n := strings.Count(ics, "BEGIN:VTODO"); n != 3 {
+		t.Errorf("exported %d to-dos, want 3", n)
+	}
+	if n := strings.Count(ics, "BEGIN:VJOURNAL"); n != 2 {
+		t.Errorf("exported %d journal entries, want 2", n)
+	}
+}
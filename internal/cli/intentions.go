@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/benhsm/goalie/internal/data"
+	"github.com/benhsm/goalie/internal/ui/today"
+)
+
+func runAdd(c *cli, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	date := c.dateFlag(fs)
+	asJSON := fs.Bool("json", false, "print the added intentions as JSON")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errors.New("nothing to add")
+	}
+	day, err := parseDate(*date)
+	if err != nil {
+		return err
+	}
+
+	whys, err := c.whys()
+	if err != nil {
+		return err
+	}
+	added, err := today.ParseIntentions(whys, strings.Join(args, "\n"))
+	if err != nil {
+		return err
+	}
+	existing, err := c.intentions(day)
+	if err != nil {
+		return err
+	}
+	// intentions for later days are planned, and confirmed when that day's
+	// intentions are set
+	planned := day.After(c.config.Day.CurrentDay())
+	for i := range added {
+		added[i].Date = day
+		added[i].Position = len(existing) + i
+		added[i].Planned = planned
+	}
+	if err := c.store.UpsertIntentions(added); err != nil {
+		return err
+	}
+	return c.printIntentions(added, len(existing)+1, *asJSON)
+}
+
+func runList(c *cli, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	date := c.dateFlag(fs)
+	asJSON := fs.Bool("json", false, "print intentions as JSON")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	day, err := parseDate(*date)
+	if err != nil {
+		return err
+	}
+	intentions, err := c.intentions(day)
+	if err != nil {
+		return err
+	}
+	return c.printIntentions(intentions, 1, *asJSON)
+}
+
+func runDone(c *cli, args []string) error {
+	fs := flag.NewFlagSet("done", flag.ContinueOnError)
+	date := c.dateFlag(fs)
+	asJSON := fs.Bool("json", false, "print the intention as JSON")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	return c.modifyIntention(*date, args, *asJSON, func(i *data.Intention) {
+		i.Done = true
+	})
+}
+
+func runPomo(c *cli, args []string) error {
+	fs := flag.NewFlagSet("pomo", flag.ContinueOnError)
+	date := c.dateFlag(fs)
+	count := fs.Int("count", 1, "the number of pomos to add, or remove if negative")
+	asJSON := fs.Bool("json", false, "print the intention as JSON")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	return c.modifyIntention(*date, args, *asJSON, func(i *data.Intention) {
+		i.Pomos += *count
+		if i.Pomos < 0 {
+			i.Pomos = 0
+		}
+	})
+}
+
+// modifyIntention applies modify to the intention numbered by args on the
+// given day, saves it and prints the result
+func (c *cli) modifyIntention(date string, args []string, asJSON bool, modify func(*data.Intention)) error {
+	if len(args) != 1 {
+		return errNoNumber
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errNoNumber
+	}
+	day, err := parseDate(date)
+	if err != nil {
+		return err
+	}
+	intentions, err := c.intentions(day)
+	if err != nil {
+		return err
+	}
+	if n < 1 || n > len(intentions) {
+		return fmt.Errorf("no intention %d on %s", n, date)
+	}
+
+	intention := intentions[n-1]
+	modify(&intention)
+	if err := c.store.UpsertIntentions([]data.Intention{intention}); err != nil {
+		return err
+	}
+	return c.printIntentions([]data.Intention{intention}, n, asJSON)
+}
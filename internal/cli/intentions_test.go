@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/benhsm/goalie/internal/config"
+	"github.com/benhsm/goalie/internal/data"
+)
+
+// run runs goalie with args against store, returning what it printed
+func run(t *testing.T, store data.Store, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := Run(store, config.Default(), args, &out)
+	return out.String(), err
+}
+
+func TestIntentionCommands(t *testing.T) {
+	store := newTestStore(t)
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"add", "--date", "2026-10-15", "w, W) draft", "&) rest"}, " 2. [ ] w) draft\n 3. [ ] &) rest\n"},
+		{[]string{"done", "2", "--date", "2026-10-15"}, " 2. [x] w) draft\n"},
+		{[]string{"pomo", "--date", "2026-10-15", "--count", "3", "2"}, " 2. [x] w) draft (3 pomos)\n"},
+		{[]string{"pomo", "--date", "2026-10-15", "--count", "-5", "2"}, " 2. [x] w) draft\n"},
+		{[]string{"list", "--date", "2026-10-15"}, " 1. [x] w) write the report\n 2. [x] w) draft\n 3. [ ] &) rest\n"},
+		// intentions for later days are planned
+		{[]string{"add", "--date", "2099-01-01", "w) someday"}, " 1. [ ] w) someday (planned)\n"},
+	}
+	for _, tt := range tests {
+		got, err := run(t, store, tt.args...)
+		if err != nil {
+			t.Errorf("goalie %s: %v", strings.Join(tt.args, " "), err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("goalie %s printed %q, want %q", strings.Join(tt.args, " "), got, tt.want)
+		}
+	}
+}
+
+func TestIntentionCommandsJSON(t *testing.T) {
+	store := newTestStore(t)
+	out, err := run(t, store, "list", "--json", "--date", "2026-10-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []intentionJSON
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("%v:\n%s", err, out)
+	}
+	want := []intentionJSON{{Number: 1, Date: "2026-10-15", Content: "w) write the report", Done: true, Goals: []string{"Work"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// nothing listed is still a list
+	if out, err := run(t, store, "list", "--json", "--date", "2026-10-01"); err != nil || strings.TrimSpace(out) != "[]" {
+		t.Errorf("listing an empty day printed %q, %v", out, err)
+	}
+}
+
+func TestIntentionCommandErrors(t *testing.T) {
+	store := newTestStore(t)
+	for _, args := range [][]string{
+		{"add"},
+		{"add", "x) unknown goal"},
+		{"add", "--date", "15/10/2026", "w) draft"},
+		{"done"},
+		{"done", "first"},
+		{"done", "--date", "2026-10-15", "2"},
+		{"pomo", "--date", "2026-10-15", "0"},
+		{"frobnicate"},
+	} {
+		if _, err := run(t, store, args...); err == nil {
+			t.Errorf("goalie %s succeeded", strings.Join(args, " "))
+		}
+	}
+}
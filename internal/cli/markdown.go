@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benhsm/goalie/internal/data"
+)
+
+// writeMarkdown writes a journal of the days from start up to and including
+// end, with a section for each day which has intentions or reviews
+func (c *cli) writeMarkdown(w io.Writer, start, end time.Time) error {
+	whys, err := c.store.GetWhys(data.All)
+	if err != nil {
+		return err
+	}
+	sort.Slice(whys, func(i, j int) bool {
+		return whys[i].Number < whys[j].Number
+	})
+	// intentions and reviews without a goal get a section of their own
+	whys = append(whys, data.Why{Name: "Other"})
+	intentions, err := c.store.GetIntentionsBetween(start, end)
+	if err != nil {
+		return err
+	}
+	days, err := c.store.GetDayReviewsBetween(start, end)
+	if err != nil {
+		return err
+	}
+
+	byDate := make(map[string][]data.Intention)
+	for _, i := range intentions {
+		k := i.Date.Format(dateLayout)
+		byDate[k] = append(byDate[k], i)
+	}
+	reviewsByDate := make(map[string][]data.Day)
+	for _, d := range days {
+		k := d.Date.Format(dateLayout)
+		reviewsByDate[k] = append(reviewsByDate[k], d)
+	}
+
+	fmt.Fprintf(w, "# Journal %s to %s\n", start.Format(dateLayout), end.Format(dateLayout))
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		k := day.Format(dateLayout)
+		if len(byDate[k]) == 0 && len(reviewsByDate[k]) == 0 {
+			continue
+		}
+		writeMarkdownDay(w, day, whys, byDate[k], reviewsByDate[k])
+	}
+	return nil
+}
+
+// writeMarkdownDay writes a day's intentions and reviews grouped into a
+// section for each of whys
+func writeMarkdownDay(w io.Writer, day time.Time, whys []data.Why, intentions []data.Intention, reviews []data.Day) {
+	sort.Slice(intentions, func(i, j int) bool {
+		return intentions[i].Position < intentions[j].Position
+	})
+	grouped := make(map[uint][]data.Intention)
+	for _, i := range intentions {
+		if len(i.Whys) == 0 {
+			grouped[0] = append(grouped[0], i)
+		}
+		for _, why := range i.Whys {
+			grouped[why.ID] = append(grouped[why.ID], i)
+		}
+	}
+	reviewed := make(map[uint]data.Day)
+	for _, r := range reviews {
+		reviewed[r.WhyID] = r
+	}
+
+	pomos := 0
+	for _, i := range intentions {
+		pomos += i.Pomos
+	}
+	fmt.Fprintf(w, "\n## %s\n", day.Format("Monday, January 2, 2006"))
+	if pomos > 0 {
+		fmt.Fprintf(w, "\n%s\n", pomodoros(pomos))
+	}
+
+	for _, why := range whys {
+		review, hasReview := reviewed[why.ID]
+		if len(grouped[why.ID]) == 0 && !hasReview {
+			continue
+		}
+		fmt.Fprintf(w, "\n### %s\n", why.Name)
+		if len(grouped[why.ID]) > 0 {
+			fmt.Fprintln(w)
+		}
+		for _, i := range grouped[why.ID] {
+			fmt.Fprintln(w, markdownIntention(i))
+		}
+		if !hasReview {
+			continue
+		}
+		if why.ID != 0 {
+			answer := "no"
+			if review.Enough {
+				answer = "yes"
+			}
+			fmt.Fprintf(w, "\nDid enough: %s\n", answer)
+		}
+		if review.Reflection != "" {
+			fmt.Fprintln(w)
+			for _, line := range strings.Split(review.Reflection, "\n") {
+				fmt.Fprintln(w, strings.TrimRight("> "+line, " "))
+			}
+		}
+	}
+}
+
+func markdownIntention(i data.Intention) string {
+	var b strings.Builder
+	content := withoutPrefix(i.Content)
+	switch {
+	case i.Cancelled:
+		fmt.Fprintf(&b, "- [ ] ~~%s~~", content)
+	case i.Done:
+		fmt.Fprintf(&b, "- [x] %s", content)
+	default:
+		fmt.Fprintf(&b, "- [ ] %s", content)
+	}
+	if i.Pomos > 0 {
+		fmt.Fprintf(&b, " (%s)", pomodoros(i.Pomos))
+	}
+	if i.Unintended {
+		b.WriteString(" *(unplanned)*")
+	}
+	return b.String()
+}
+
+func pomodoros(n int) string {
+	if n == 1 {
+		return "1 pomodoro"
+	}
+	return fmt.Sprintf("%d pomodoros", n)
+}
+
+// withoutPrefix strips the goal prefix, such as "0,2)", from an intention's
+// content, since the section it appears in already says which goal it is for
+func withoutPrefix(content string) string {
+	_, rest, _ := data.SplitPrefix(content)
+	return rest
+}
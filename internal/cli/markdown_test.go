@@ -0,0 +1,21 @@
+package cli
+
+import "testing"
+
+func TestWithoutPrefix(t *testing.T) {
+	tests := []struct {
+		content, want string
+	}{
+		{"0,2) write the report", "write the report"},
+		{"w) call bob (work)", "call bob (work)"},
+		{"&) tidy up", "tidy up"},
+		{"call bob (work)", "call bob (work)"},
+		{"see(work) later", "see(work) later"},
+		{"no prefix at all", "no prefix at all"},
+	}
+	for _, tt := range tests {
+		if got := withoutPrefix(tt.content); got != tt.want {
+			t.Errorf("withoutPrefix(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/benhsm/goalie/internal/data"
+	"github.com/benhsm/goalie/internal/ui/today"
+)
+
+func runRecur(c *cli, args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected add, list or rm, see 'goalie help'")
+	}
+	switch args[0] {
+	case "add":
+		return runRecurAdd(c, args[1:])
+	case "list":
+		return runRecurList(c, args[1:])
+	case "rm":
+		return runRecurRm(c, args[1:])
+	}
+	return fmt.Errorf("unknown recur command %q, expected add, list or rm", args[0])
+}
+
+func runRecurAdd(c *cli, args []string) error {
+	fs := flag.NewFlagSet("recur add", flag.ContinueOnError)
+	date := fs.String("date", c.config.Day.CurrentDay().Format(dateLayout), "the first day the intention recurs on")
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return errors.New("expected a schedule and an intention")
+	}
+	start, err := parseDate(*date)
+	if err != nil {
+		return err
+	}
+	schedule, err := data.ParseSchedule(args[0], start)
+	if err != nil {
+		return err
+	}
+
+	whys, err := c.whys()
+	if err != nil {
+		return err
+	}
+	parsed, err := today.ParseIntentions(whys, strings.Join(args[1:], " "))
+	if err != nil {
+		return err
+	}
+	rule := data.RecurringIntention{
+		Content:  withoutPrefix(parsed[0].Content),
+		Schedule: schedule,
+		Whys:     parsed[0].Whys,
+	}
+	if err := c.store.UpsertRecurringIntentions([]data.RecurringIntention{rule}); err != nil {
+		return err
+	}
+	rules, err := c.store.GetRecurringIntentions()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, formatRecurring(len(rules), rules[len(rules)-1]))
+	return nil
+}
+
+// recurringJSON is the form in which recurring intentions are printed with
+// --json
+type recurringJSON struct {
+	Number   int      `json:"number"`
+	Schedule string   `json:"schedule"`
+	Start    string   `json:"start"`
+	Content  string   `json:"content"`
+	Goals    []string `json:"goals"`
+}
+
+func runRecurList(c *cli, args []string) error {
+	fs := flag.NewFlagSet("recur list", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print recurring intentions as JSON")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	rules, err := c.store.GetRecurringIntentions()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		result := []recurringJSON{}
+		for n, rule := range rules {
+			goals := []string{}
+			for _, why := range rule.Whys {
+				goals = append(goals, why.Name)
+			}
+			result = append(result, recurringJSON{
+				Number:   n + 1,
+				Schedule: rule.Schedule.String(),
+				Start:    rule.Schedule.Start.Format(dateLayout),
+				Content:  rule.Content,
+				Goals:    goals,
+			})
+		}
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	for n, rule := range rules {
+		fmt.Fprintln(c.out, formatRecurring(n+1, rule))
+	}
+	return nil
+}
+
+func runRecurRm(c *cli, args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected the number of a recurring intention, as shown by 'goalie recur list'")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errors.New("expected the number of a recurring intention, as shown by 'goalie recur list'")
+	}
+	rules, err := c.store.GetRecurringIntentions()
+	if err != nil {
+		return err
+	}
+	if n < 1 || n > len(rules) {
+		return fmt.Errorf("no recurring intention %d", n)
+	}
+	return c.store.DeleteRecurringIntention(rules[n-1])
+}
+
+func formatRecurring(n int, r data.RecurringIntention) string {
+	var goals []string
+	for _, why := range r.Whys {
+		goals = append(goals, why.Name)
+	}
+	s := fmt.Sprintf("%2d. %-14s %s", n, r.Schedule, r.Content)
+	if len(goals) > 0 {
+		s += " (" + strings.Join(goals, ", ") + ")"
+	}
+	return s
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// runTimezone prints the time zone in which the profile's days are counted,
+// after changing it to the one named, if any
+func runTimezone(c *cli, args []string) error {
+	fs := flag.NewFlagSet("timezone", flag.ContinueOnError)
+	args, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return errors.New("expected at most one time zone")
+	}
+	if len(args) == 1 {
+		if err := c.store.SetLocation(args[0]); err != nil {
+			return err
+		}
+	}
+	loc, err := c.store.Location()
+	if err != nil {
+		return err
+	}
+	c.config.Day.Location = loc
+	fmt.Fprintf(c.out, "%s (the current day is %s)\n", loc, c.config.Day.CurrentDay().Format(dateLayout))
+	return nil
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benhsm/goalie/internal/data"
+	"github.com/benhsm/goalie/internal/todotxt"
+	"github.com/benhsm/goalie/internal/ui/today"
+)
+
+// toTask converts an intention to a todo.txt task. Its goals become projects,
+// and the intention's date serves as both its creation and completion date.
+func toTask(i data.Intention) todotxt.Task {
+	task := todotxt.Task{
+		Done:    i.Done || i.Cancelled,
+		Created: i.Date,
+		Text:    i.Content,
+		Tags:    make(map[string]string),
+	}
+	if task.Done {
+		task.Completed = i.Date
+	}
+	for _, why := range i.Whys {
+		task.Projects = append(task.Projects, todotxt.Project(why.Name))
+	}
+	if i.Pomos > 0 {
+		task.Tags["pomo"] = strconv.Itoa(i.Pomos)
+	}
+	if i.Cancelled {
+		task.Tags["cancelled"] = "yes"
+	}
+	return task
+}
+
+// fromTask converts a todo.txt task to an intention on the day it was
+// created, or else completed, or on day if it has neither date. A goal prefix
+// like "0,2)" is read as it is when adding intentions, and projects named
+// after goals link the intention to them too, the content being given the
+// prefix of every goal it is linked to. Other projects and tags are kept in
+// the content.
+func fromTask(task todotxt.Task, whys []data.Why, day time.Time) (data.Intention, error) {
+	intention := data.Intention{Content: task.Text}
+	if _, _, ok := data.SplitPrefix(task.Text); ok {
+		parsed, err := today.ParseIntentions(whys, task.Text)
+		if err != nil {
+			return intention, err
+		}
+		intention = parsed[0]
+	}
+	_, content, _ := data.SplitPrefix(intention.Content)
+
+	for _, project := range task.Projects {
+		var why *data.Why
+		for j := range whys {
+			if strings.EqualFold(todotxt.Project(whys[j].Name), project) {
+				why = &whys[j]
+			}
+		}
+		if why == nil {
+			content += " +" + project
+			continue
+		}
+		linked := false
+		for _, w := range intention.Whys {
+			linked = linked || w.ID == why.ID
+		}
+		if !linked {
+			intention.Whys = append(intention.Whys, why)
+		}
+	}
+
+	intention.Date = day
+	switch {
+	case !task.Created.IsZero():
+		intention.Date = task.Created
+	case !task.Completed.IsZero():
+		intention.Date = task.Completed
+	}
+	if task.Tags["cancelled"] == "yes" {
+		intention.Cancelled = true
+	} else {
+		intention.Done = task.Done
+	}
+	if pomo, ok := task.Tags["pomo"]; ok {
+		n, err := strconv.Atoi(pomo)
+		if err != nil || n < 0 {
+			return intention, fmt.Errorf("invalid pomo count %q", pomo)
+		}
+		intention.Pomos = n
+	}
+
+	var keys []string
+	for k := range task.Tags {
+		if k != "pomo" && k != "cancelled" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		content += " " + k + ":" + task.Tags[k]
+	}
+	intention.Content = data.Prefix(intention.Whys) + " " + content
+	return intention, nil
+}
+
+// writeTodoTxt writes the intentions of the days from start up to and
+// including end as todo.txt lines
+func (c *cli) writeTodoTxt(w io.Writer, start, end time.Time) error {
+	intentions, err := c.store.GetIntentionsBetween(start, end)
+	if err != nil {
+		return err
+	}
+	for _, i := range intentions {
+		fmt.Fprintln(w, toTask(i))
+	}
+	return nil
+}
+
+// importTodoTxt adds an intention for each line of contents, placing those
+// without a creation or completion date on day. Lines matching an intention
+// already on the same day are skipped. With dryRun, nothing is saved.
+func (c *cli) importTodoTxt(contents string, day time.Time, dryRun bool) (data.ImportStats, error) {
+	stats := data.ImportStats{Added: map[string]int{}, Skipped: map[string]int{}}
+	whys, err := c.whys()
+	if err != nil {
+		return stats, err
+	}
+
+	var added []data.Intention
+	existing := make(map[string][]data.Intention)
+	for n, line := range strings.Split(contents, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		task, err := todotxt.Parse(line, time.UTC)
+		if err != nil {
+			return stats, fmt.Errorf("line %d: %w", n+1, err)
+		}
+		intention, err := fromTask(task, whys, day)
+		if err != nil {
+			return stats, fmt.Errorf("line %d: %w", n+1, err)
+		}
+
+		k := intention.Date.Format(dateLayout)
+		if _, ok := existing[k]; !ok {
+			existing[k], err = c.intentions(intention.Date)
+			if err != nil {
+				return stats, err
+			}
+		}
+		duplicate := false
+		for _, e := range existing[k] {
+			duplicate = duplicate || e.Content == intention.Content
+		}
+		if duplicate {
+			stats.Skipped["intentions"]++
+			continue
+		}
+		intention.Position = len(existing[k])
+		existing[k] = append(existing[k], intention)
+		added = append(added, intention)
+		stats.Added["intentions"]++
+	}
+
+	if len(added) == 0 || dryRun {
+		return stats, nil
+	}
+	return stats, c.store.UpsertIntentions(added)
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benhsm/goalie/internal/data"
+	"github.com/benhsm/goalie/internal/todotxt"
+)
+
+func TestFromTask(t *testing.T) {
+	whys := []data.Why{
+		{ID: 1, Name: "Work", Code: "w"},
+		{ID: 2, Name: "Health", Code: "h"},
+	}
+	fallback := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		line    string
+		content string
+		whyIDs  []uint
+		date    string
+		done    bool
+	}{
+		{"2026-10-15 write report +Work", "w) write report", []uint{1}, "2026-10-15", false},
+		{"w) write report +Work +Health", "w,h) write report", []uint{1, 2}, "2026-10-18", false},
+		{"call bob (work) +Side", "&) call bob (work) +Side", nil, "2026-10-18", false},
+		{"x 2026-10-16 stretch +Health pomo:2", "h) stretch", []uint{2}, "2026-10-16", true},
+		{"x 2026-10-16 2026-10-15 stretch +Health", "h) stretch", []uint{2}, "2026-10-15", true},
+		{"h) stretch due:friday", "h) stretch due:friday", []uint{2}, "2026-10-18", false},
+	}
+	for _, tt := range tests {
+		task, err := todotxt.Parse(tt.line, time.UTC)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.line, err)
+		}
+		got, err := fromTask(task, whys, fallback)
+		if err != nil {
+			t.Errorf("fromTask(%q): %v", tt.line, err)
+			continue
+		}
+		if got.Content != tt.content {
+			t.Errorf("fromTask(%q) content = %q, want %q", tt.line, got.Content, tt.content)
+		}
+		var ids []uint
+		for _, why := range got.Whys {
+			ids = append(ids, why.ID)
+		}
+		if len(ids) != len(tt.whyIDs) {
+			t.Errorf("fromTask(%q) goals = %v, want %v", tt.line, ids, tt.whyIDs)
+		} else {
+			for i := range ids {
+				if ids[i] != tt.whyIDs[i] {
+					t.Errorf("fromTask(%q) goals = %v, want %v", tt.line, ids, tt.whyIDs)
+					break
+				}
+			}
+		}
+		if d := got.Date.Format(dateLayout); d != tt.date {
+			t.Errorf("fromTask(%q) date = %s, want %s", tt.line, d, tt.date)
+		}
+		if got.Done != tt.done {
+			t.Errorf("fromTask(%q) done = %v, want %v", tt.line, got.Done, tt.done)
+		}
+	}
+
+	task, _ := todotxt.Parse("x) unknown goal", time.UTC)
+	if _, err := fromTask(task, whys, fallback); err == nil {
+		t.Error("fromTask accepted a prefix with an unknown goal code")
+	}
+}
+
+func TestTaskRoundTrip(t *testing.T) {
+	whys := []data.Why{{ID: 1, Name: "Deep Work", Code: "w"}}
+	intention := data.Intention{
+		Date:    time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+		Content: "w) write report",
+		Done:    true,
+		Pomos:   3,
+		Whys:    []*data.Why{&whys[0]},
+	}
+	task, err := todotxt.Parse(toTask(intention).String(), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := fromTask(task, whys, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Content != intention.Content || !got.Date.Equal(intention.Date) ||
+		got.Done != intention.Done || got.Pomos != intention.Pomos || len(got.Whys) != 1 {
+		t.Errorf("round trip gave %+v, want %+v", got, intention)
+	}
+}
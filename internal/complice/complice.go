@@ -0,0 +1,311 @@
+// Package complice converts data exported from Complice (complice.co), on
+// which goalie is modeled, into goalie's export document so that it can be
+// imported like a goalie backup.
+//
+// Complice doesn't document its export formats, so these are the shapes the
+// parser assumes, as in the samples in testdata. Two kinds of file are read.
+// A JSON export has the form
+//
+//	{
+//	  "goals": [{"code": 1, "name": "...", "color": "#...", "archived": false}],
+//	  "days": [{
+//	    "date": "2019-05-01",
+//	    "intentions": [{"text": "1,2) ...", "code": 1, "completed": true,
+//	      "nixed": false, "pomos": 2, "outcome": false}],
+//	    "outcomes": [{"code": 1, "text": "...", "enough": true}],
+//	    "reflection": "..."
+//	  }]
+//	}
+//
+// Goal codes may be numbers or strings. Dates may be timestamps, of which
+// only the date is read. Every field but a goal's code and name, a day's date
+// and an intention's text is optional. An intention is linked to the goals
+// in the prefix of its text, such as both 1 and 2 in "1,2) ...", or to the
+// goal with its code if its text has no prefix.
+//
+// A CSV export has a header row naming its columns, in any order and case,
+// of which date and text are required and code, goal, color, completed,
+// nixed and pomos are optional. Each other row is an intention, and goals are
+// taken from the distinct code and goal columns. Booleans are written as
+// true/false, yes/no, 1/0 or x for true.
+package complice
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benhsm/goalie/internal/data"
+)
+
+type export struct {
+	Goals []goal `json:"goals"`
+	Days  []day  `json:"days"`
+}
+
+type goal struct {
+	Code     code   `json:"code"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	Archived bool   `json:"archived"`
+}
+
+type day struct {
+	Date       string      `json:"date"`
+	Intentions []intention `json:"intentions"`
+	Outcomes   []outcome   `json:"outcomes"`
+	Reflection string      `json:"reflection"`
+}
+
+type intention struct {
+	Text      string `json:"text"`
+	Code      code   `json:"code"`
+	Completed bool   `json:"completed"`
+	Nixed     bool   `json:"nixed"`
+	Pomos     int    `json:"pomos"`
+	Outcome   bool   `json:"outcome"`
+}
+
+type outcome struct {
+	Code   code   `json:"code"`
+	Text   string `json:"text"`
+	Enough bool   `json:"enough"`
+}
+
+// code is a goal's code, which may be written as a number or a string
+type code string
+
+func (c *code) UnmarshalJSON(b []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err == nil {
+		*c = code(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("invalid goal code %s", b)
+	}
+	*c = code(strings.TrimSpace(s))
+	return nil
+}
+
+// defaultColor is given to goals whose color isn't exported
+const defaultColor = "#808080"
+
+// Parse reads a Complice JSON or CSV export
+func Parse(contents []byte) (data.Export, error) {
+	var e export
+	var err error
+	if trimmed := bytes.TrimSpace(contents); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(contents, &e)
+	} else {
+		e, err = parseCSV(contents)
+	}
+	if err != nil {
+		return data.Export{}, err
+	}
+	return convert(e)
+}
+
+func parseCSV(contents []byte) (export, error) {
+	var e export
+	r := csv.NewReader(bytes.NewReader(contents))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return e, err
+	}
+	if len(records) == 0 {
+		return e, fmt.Errorf("empty CSV file")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "text"} {
+		if _, ok := columns[required]; !ok {
+			return e, fmt.Errorf("CSV file has no %q column", required)
+		}
+	}
+
+	goals := make(map[code]*goal)
+	byDate := make(map[string]*day)
+	var dates []string
+	for n, record := range records[1:] {
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		line := n + 2
+
+		i := intention{Text: field("text"), Code: code(field("code"))}
+		if i.Text == "" {
+			continue
+		}
+		if i.Completed, err = parseBool(field("completed")); err != nil {
+			return e, fmt.Errorf("line %d: %w", line, err)
+		}
+		if i.Nixed, err = parseBool(field("nixed")); err != nil {
+			return e, fmt.Errorf("line %d: %w", line, err)
+		}
+		if pomos := field("pomos"); pomos != "" {
+			if i.Pomos, err = strconv.Atoi(pomos); err != nil {
+				return e, fmt.Errorf("line %d: invalid pomos %q", line, pomos)
+			}
+		}
+		if name := field("goal"); name != "" && i.Code != "" && goals[i.Code] == nil {
+			goals[i.Code] = &goal{Code: i.Code, Name: name, Color: field("color")}
+		}
+
+		date := field("date")
+		if byDate[date] == nil {
+			byDate[date] = &day{Date: date}
+			dates = append(dates, date)
+		}
+		byDate[date].Intentions = append(byDate[date].Intentions, i)
+	}
+
+	for _, g := range goals {
+		e.Goals = append(e.Goals, *g)
+	}
+	for _, date := range dates {
+		e.Days = append(e.Days, *byDate[date])
+	}
+	return e, nil
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "", "0", "false", "no", "n":
+		return false, nil
+	case "1", "true", "yes", "y", "x":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", s)
+}
+
+// convert maps a Complice export onto goalie's document. Goals are numbered in
+// the order of their codes, and intentions keep their text, including the
+// goal prefix, as it was in Complice.
+func convert(e export) (data.Export, error) {
+	doc := data.Export{Version: data.ExportVersion, Exported: time.Now()}
+
+	sort.SliceStable(e.Goals, func(i, j int) bool {
+		return codeLess(e.Goals[i].Code, e.Goals[j].Code)
+	})
+	ids := make(map[code]uint)
+	for n, g := range e.Goals {
+		if g.Name == "" {
+			return doc, fmt.Errorf("goal %s has no name", g.Code)
+		}
+		if _, ok := ids[g.Code]; ok {
+			return doc, fmt.Errorf("more than one goal has the code %s", g.Code)
+		}
+		color := g.Color
+		if color == "" {
+			color = defaultColor
+		}
+		id := uint(n + 1)
+		ids[g.Code] = id
+		doc.Whys = append(doc.Whys, data.ExportWhy{
+			ID:       id,
+			Name:     g.Name,
+			Code:     string(g.Code),
+			Number:   n,
+			Color:    color,
+			Archived: g.Archived,
+		})
+	}
+
+	for _, d := range e.Days {
+		date, err := parseDate(d.Date)
+		if err != nil {
+			return doc, err
+		}
+		for position, i := range d.Intentions {
+			codes, _, hasPrefix := data.SplitPrefix(i.Text)
+			if !hasPrefix && i.Code != "" {
+				codes = []string{string(i.Code)}
+			}
+			whyIDs := []uint{}
+			var linked []string
+			for _, c := range codes {
+				if id, ok := ids[code(c)]; ok {
+					whyIDs = append(whyIDs, id)
+					linked = append(linked, c)
+				}
+			}
+			content := i.Text
+			if !hasPrefix {
+				// intentions in goalie always start with a prefix
+				prefix := data.NoGoalPrefix
+				if len(linked) > 0 {
+					prefix = strings.Join(linked, ",") + ")"
+				}
+				content = prefix + " " + content
+			}
+			doc.Intentions = append(doc.Intentions, data.ExportIntention{
+				ID:        uint(len(doc.Intentions) + 1),
+				Date:      date,
+				Content:   content,
+				Done:      i.Completed,
+				Cancelled: i.Nixed,
+				Outcome:   i.Outcome,
+				Position:  position,
+				Pomos:     i.Pomos,
+				WhyIDs:    whyIDs,
+			})
+		}
+		for _, o := range d.Outcomes {
+			id, ok := ids[o.Code]
+			if !ok {
+				return doc, fmt.Errorf("outcome on %s: no goal with code %s", d.Date, o.Code)
+			}
+			doc.Days = append(doc.Days, data.ExportDay{
+				Date:       date,
+				WhyID:      id,
+				Enough:     o.Enough,
+				Reflection: o.Text,
+			})
+		}
+		if d.Reflection != "" {
+			doc.Days = append(doc.Days, data.ExportDay{Date: date, Reflection: d.Reflection})
+		}
+	}
+	return doc, nil
+}
+
+// parseDate accepts a date alone or at the start of a timestamp, returning it
+// in the layout of goalie's export document
+func parseDate(s string) (string, error) {
+	if len(s) >= 10 {
+		if _, err := time.Parse("2006-01-02", s[:10]); err == nil {
+			return s[:10], nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+}
+
+// codeLess orders numeric codes numerically and before any others
+func codeLess(a, b code) bool {
+	x, errA := strconv.Atoi(string(a))
+	y, errB := strconv.Atoi(string(b))
+	switch {
+	case errA == nil && errB == nil:
+		return x < y
+	case errA == nil:
+		return true
+	case errB == nil:
+		return false
+	}
+	return a < b
+}
@@ -0,0 +1,155 @@
+package complice
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/benhsm/goalie/internal/data"
+)
+
+func parseFile(t *testing.T, name string) data.Export {
+	t.Helper()
+	contents, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(contents)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", name, err)
+	}
+	return doc
+}
+
+// goalCodes returns the codes of the goals an intention is linked to
+func goalCodes(doc data.Export, i data.ExportIntention) []string {
+	codes := []string{}
+	for _, id := range i.WhyIDs {
+		for _, why := range doc.Whys {
+			if why.ID == id {
+				codes = append(codes, why.Code)
+			}
+		}
+	}
+	return codes
+}
+
+func TestParseJSON(t *testing.T) {
+	doc := parseFile(t, "testdata/export.json")
+
+	var names []string
+	for _, why := range doc.Whys {
+		names = append(names, why.Code+" "+why.Name)
+	}
+	// goals are numbered in the order of their codes
+	if want := []string{"1 Work", "2 Health", "3 Old side project"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("goals = %v, want %v", names, want)
+	}
+	if !doc.Whys[2].Archived || doc.Whys[2].Color != defaultColor {
+		t.Errorf("archived goal = %+v", doc.Whys[2])
+	}
+
+	tests := []struct {
+		content string
+		codes   []string
+		date    string
+		done    bool
+		cancel  bool
+		outcome bool
+		pomos   int
+	}{
+		{"1) write the report", []string{"1"}, "2019-05-01", true, false, false, 2},
+		{"1,2) walk to the meeting", []string{"1", "2"}, "2019-05-01", true, false, false, 0},
+		{"1) call bob (work)", []string{"1"}, "2019-05-01", false, true, false, 0},
+		{"&) tidy the desk", []string{}, "2019-05-01", false, false, false, 0},
+		{"1) finished the report early", []string{"1"}, "2019-05-01", false, false, true, 0},
+		{"2) run", []string{"2"}, "2019-05-02", false, false, false, 0},
+	}
+	if len(doc.Intentions) != len(tests) {
+		t.Fatalf("got %d intentions, want %d", len(doc.Intentions), len(tests))
+	}
+	for n, tt := range tests {
+		i := doc.Intentions[n]
+		if i.Content != tt.content || i.Date != tt.date || i.Done != tt.done ||
+			i.Cancelled != tt.cancel || i.Outcome != tt.outcome || i.Pomos != tt.pomos {
+			t.Errorf("intention %d = %+v, want %+v", n, i, tt)
+		}
+		if codes := goalCodes(doc, i); !reflect.DeepEqual(codes, tt.codes) {
+			t.Errorf("intention %q linked to %v, want %v", i.Content, codes, tt.codes)
+		}
+	}
+
+	wantDays := []data.ExportDay{
+		{Date: "2019-05-01", WhyID: 1, Enough: true, Reflection: "good progress"},
+		{Date: "2019-05-01", WhyID: 2, Enough: false, Reflection: "only walked"},
+		{Date: "2019-05-01", Reflection: "a busy day"},
+	}
+	if !reflect.DeepEqual(doc.Days, wantDays) {
+		t.Errorf("days = %+v, want %+v", doc.Days, wantDays)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	doc := parseFile(t, "testdata/export.csv")
+	if len(doc.Whys) != 2 || doc.Whys[0].Name != "Work" || doc.Whys[1].Name != "Health" {
+		t.Errorf("goals = %+v", doc.Whys)
+	}
+	tests := []struct {
+		content string
+		codes   []string
+		done    bool
+		cancel  bool
+	}{
+		{"1) write the report", []string{"1"}, true, false},
+		{"1,2) walk to the meeting", []string{"1", "2"}, true, false},
+		{"2) stretch", []string{"2"}, false, true},
+		{"&) tidy the desk", []string{}, false, false},
+	}
+	if len(doc.Intentions) != len(tests) {
+		t.Fatalf("got %d intentions, want %d", len(doc.Intentions), len(tests))
+	}
+	for n, tt := range tests {
+		i := doc.Intentions[n]
+		if i.Content != tt.content || i.Done != tt.done || i.Cancelled != tt.cancel {
+			t.Errorf("intention %d = %+v, want %+v", n, i, tt)
+		}
+		if codes := goalCodes(doc, i); !reflect.DeepEqual(codes, tt.codes) {
+			t.Errorf("intention %q linked to %v, want %v", i.Content, codes, tt.codes)
+		}
+	}
+	if doc.Intentions[3].Date != "2019-05-02" || doc.Intentions[0].Pomos != 2 {
+		t.Errorf("intentions = %+v", doc.Intentions)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		`{"goals": [{"code": 1}]}`,
+		`{"goals": [{"code": 1, "name": "a"}, {"code": "1", "name": "b"}]}`,
+		`{"days": [{"date": "May 1", "intentions": [{"text": "x"}]}]}`,
+		`{"days": [{"date": "2019-05-01", "outcomes": [{"code": 9}]}]}`,
+		"text\nfoo\n",
+		"date,text,pomos\n2019-05-01,run,many\n",
+	}
+	for _, contents := range tests {
+		if _, err := Parse([]byte(contents)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", contents)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	doc := parseFile(t, "testdata/export.json")
+	dir := t.TempDir()
+	store, err := data.NewStore(dir + "/goalie.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := store.Import(doc, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Added["intentions"] != 6 || stats.Added["whys"] != 3 {
+		t.Errorf("added %v", stats.Added)
+	}
+}
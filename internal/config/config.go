@@ -0,0 +1,207 @@
+// Package config loads goalie's optional configuration file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every user-configurable setting. The zero value is not
+// useful; start from Default.
+type Config struct {
+	Day        DayConfig        `yaml:"day"`
+	Appearance AppearanceConfig `yaml:"appearance"`
+	Pomodoro   PomodoroConfig   `yaml:"pomodoro"`
+
+	// Keys remaps keybindings. It maps page names to action names to the keys
+	// which should trigger that action, replacing the defaults.
+	Keys map[string]map[string][]string `yaml:"keys"`
+}
+
+type DayConfig struct {
+	// RolloverHour is the hour of the morning at which one day ends and the
+	// next begins
+	RolloverHour int `yaml:"rollover_hour"`
+
+	// Location is the time zone in which days are counted. It is stored with
+	// each profile's data rather than in the config file, and is set once the
+	// database has been opened; until then the machine's zone is used.
+	Location *time.Location `yaml:"-"`
+}
+
+type AppearanceConfig struct {
+	// Width is the width in columns of the intention lists and inputs
+	Width int `yaml:"width"`
+}
+
+type PomodoroConfig struct {
+	// Work is the length of a pomodoro
+	Work       time.Duration `yaml:"work"`
+	ShortBreak time.Duration `yaml:"short_break"`
+	LongBreak  time.Duration `yaml:"long_break"`
+	// LongBreakEvery is the number of pomodoros after which a long break is
+	// taken instead of a short one
+	LongBreakEvery int `yaml:"long_break_every"`
+}
+
+// Default returns the configuration used when there is no config file
+func Default() Config {
+	return Config{
+		Day: DayConfig{
+			RolloverHour: 4,
+		},
+		Appearance: AppearanceConfig{
+			Width: 50,
+		},
+		Pomodoro: PomodoroConfig{
+			Work:           25 * time.Minute,
+			ShortBreak:     5 * time.Minute,
+			LongBreak:      15 * time.Minute,
+			LongBreakEvery: 4,
+		},
+	}
+}
+
+// CurrentDay returns the day the user is currently living in, as midnight
+// UTC of its date in Location, which is how the data package stores days
+func (d DayConfig) CurrentDay() time.Time {
+	loc := d.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	now := time.Now().In(loc)
+
+	// For our purposes, the day is considered to begin/end at the rollover
+	// hour rather than at midnight
+	if now.Hour() < d.RolloverHour {
+		now = now.AddDate(0, 0, -1)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Path returns the location of the config file, whether or not it exists
+func Path() (string, error) {
+	return xdg.ConfigFile("goalie/config.yaml")
+}
+
+// Error describes every problem found in a config file
+type Error struct {
+	Path     string
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid config file %s:\n  %s", e.Path, strings.Join(e.Problems, "\n  "))
+}
+
+// Load reads the config file at path, filling in defaults for any settings
+// it leaves out. A missing file is not an error.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	} else if err != nil {
+		return cfg, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(contents, &node); err != nil {
+		return cfg, &Error{Path: path, Problems: []string{err.Error()}}
+	}
+	if len(node.Content) == 0 {
+		// an empty file
+		return cfg, nil
+	}
+
+	var problems []string
+	checkKeys(node.Content[0], reflect.TypeOf(cfg), "", &problems)
+	if err := node.Decode(&cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			problems = append(problems, typeErr.Errors...)
+		} else {
+			problems = append(problems, err.Error())
+		}
+	}
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		return cfg, &Error{Path: path, Problems: problems}
+	}
+	return cfg, nil
+}
+
+// checkKeys records a problem for every key in node that doesn't correspond
+// to a field of t
+func checkKeys(node *yaml.Node, t reflect.Type, prefix string, problems *[]string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, v := node.Content[i], node.Content[i+1]
+		name := prefix + k.Value
+		switch t.Kind() {
+		case reflect.Map:
+			checkKeys(v, t.Elem(), name+".", problems)
+		case reflect.Struct:
+			field, ok := fieldByTag(t, k.Value)
+			if !ok {
+				*problems = append(*problems, fmt.Sprintf("line %d: unknown key %q", k.Line, name))
+				continue
+			}
+			checkKeys(v, field.Type, name+".", problems)
+		}
+	}
+}
+
+func fieldByTag(t reflect.Type, tag string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("yaml") == tag {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func (c Config) validate() []string {
+	var problems []string
+	if c.Day.RolloverHour < 0 || c.Day.RolloverHour > 23 {
+		problems = append(problems, fmt.Sprintf("day.rollover_hour must be between 0 and 23, not %d", c.Day.RolloverHour))
+	}
+	if c.Appearance.Width < 30 {
+		problems = append(problems, fmt.Sprintf("appearance.width must be at least 30, not %d", c.Appearance.Width))
+	}
+	durations := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"pomodoro.work", c.Pomodoro.Work},
+		{"pomodoro.short_break", c.Pomodoro.ShortBreak},
+		{"pomodoro.long_break", c.Pomodoro.LongBreak},
+	}
+	for _, d := range durations {
+		if d.d < time.Second {
+			problems = append(problems, fmt.Sprintf("%s must be at least one second, not %s", d.name, d.d))
+		}
+	}
+	if c.Pomodoro.LongBreakEvery < 1 {
+		problems = append(problems, fmt.Sprintf("pomodoro.long_break_every must be at least 1, not %d", c.Pomodoro.LongBreakEvery))
+	}
+	for page, actions := range c.Keys {
+		for action, keys := range actions {
+			if len(keys) == 0 {
+				problems = append(problems, fmt.Sprintf("keys.%s.%s must list at least one key", page, action))
+			}
+		}
+	}
+	return problems
+}
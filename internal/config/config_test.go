@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// write writes contents to a config file in a temporary directory and
+// returns its path
+func write(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	custom := Default()
+	custom.Day.RolloverHour = 6
+	custom.Pomodoro.Work = 50 * time.Minute
+	custom.Keys = map[string]map[string][]string{"today": {"done": {"ctrl+d", "D"}}}
+
+	tests := []struct {
+		name     string
+		contents string
+		want     Config
+	}{
+		{"empty", "", Default()},
+		{"comments only", "# nothing set\n", Default()},
+		{"partial", `
+day:
+  rollover_hour: 6
+pomodoro:
+  work: 50m
+keys:
+  today:
+    done: [ctrl+d, D]
+`, custom},
+	}
+	for _, tt := range tests {
+		got, err := Load(write(t, tt.contents))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	got, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, Default()) {
+		t.Errorf("got %+v, want the defaults", got)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		problems []string
+	}{
+		{"syntax", "day: [", []string{"yaml:"}},
+		{"unknown key", "day:\n  rollover: 6\ncolour: red\n",
+			[]string{`line 2: unknown key "day.rollover"`, `line 3: unknown key "colour"`}},
+		{"wrong type", "appearance:\n  width: wide\n", []string{"cannot unmarshal"}},
+		{"out of range", "day:\n  rollover_hour: 24\nappearance:\n  width: 20\n",
+			[]string{"day.rollover_hour must be between 0 and 23", "appearance.width must be at least 30"}},
+		{"pomodoro", "pomodoro:\n  short_break: 0s\n  long_break_every: 0\n",
+			[]string{"pomodoro.short_break must be at least one second", "pomodoro.long_break_every must be at least 1"}},
+		{"no keys", "keys:\n  today:\n    done: []\n", []string{"keys.today.done must list at least one key"}},
+	}
+	for _, tt := range tests {
+		path := write(t, tt.contents)
+		_, err := Load(path)
+		var cfgErr *Error
+		if !errors.As(err, &cfgErr) {
+			t.Errorf("%s: got %v, want a config error", tt.name, err)
+			continue
+		}
+		if cfgErr.Path != path || len(cfgErr.Problems) != len(tt.problems) {
+			t.Errorf("%s: got problems %q, want %d", tt.name, cfgErr.Problems, len(tt.problems))
+			continue
+		}
+		for i, want := range tt.problems {
+			if !strings.Contains(cfgErr.Problems[i], want) {
+				t.Errorf("%s: problem %q doesn't mention %q", tt.name, cfgErr.Problems[i], want)
+			}
+		}
+	}
+}
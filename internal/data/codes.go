@@ -0,0 +1,217 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Each goal has a short code, such as "0" or "w", which intentions are
+// linked to it by in their prefix, as in "0,w) write the report". Codes are
+// kept when goals are reordered or archived, so that the prefixes of earlier
+// intentions go on meaning the same goals.
+
+// NoGoalPrefix is the prefix of intentions which aren't linked to a goal
+const NoGoalPrefix = "&)"
+
+// MaxCodeLength is the most characters a goal code may have
+const MaxCodeLength = 3
+
+// ValidCode returns an error if code can't be used as a goal code. Codes are
+// made of letters and digits, so that they can't be mistaken for the rest of
+// a prefix.
+func ValidCode(code string) error {
+	if code == "" {
+		return errors.New("a goal needs a code")
+	}
+	if len([]rune(code)) > MaxCodeLength {
+		return fmt.Errorf("goal code %q is longer than %d characters", code, MaxCodeLength)
+	}
+	for _, r := range code {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return fmt.Errorf("goal code %q should only have letters and digits", code)
+		}
+	}
+	return nil
+}
+
+// NextCode returns the lowest number which isn't already the code of one of
+// whys
+func NextCode(whys []Why) string {
+	used := make(map[string]bool)
+	for _, why := range whys {
+		used[strings.ToLower(why.Code)] = true
+	}
+	return nextCode(used)
+}
+
+func nextCode(used map[string]bool) string {
+	for n := 0; ; n++ {
+		if code := strconv.Itoa(n); !used[code] {
+			return code
+		}
+	}
+}
+
+// Prefix returns the prefix which links an intention to whys
+func Prefix(whys []*Why) string {
+	var codes []string
+	for _, why := range whys {
+		codes = append(codes, why.Code)
+	}
+	if len(codes) == 0 {
+		return NoGoalPrefix
+	}
+	return strings.Join(codes, ",") + ")"
+}
+
+// SplitPrefix separates the goal codes at the start of content from the rest
+// of it. ok is false if content doesn't start with a prefix, which is either
+// NoGoalPrefix or valid codes separated by commas, so that a ")" later in the
+// content isn't mistaken for the end of one.
+func SplitPrefix(content string) (codes []string, rest string, ok bool) {
+	prefix, rest, found := strings.Cut(content, ")")
+	if !found {
+		return nil, content, false
+	}
+	rest = strings.TrimSpace(rest)
+	if prefix+")" == NoGoalPrefix {
+		return nil, rest, true
+	}
+	codes = strings.Split(prefix, ",")
+	for _, code := range codes {
+		if ValidCode(code) != nil {
+			return nil, content, false
+		}
+	}
+	return codes, rest, true
+}
+
+// WithPrefix replaces the prefix of content with the one linking it to whys.
+// Content without a prefix is returned as it is.
+func WithPrefix(content string, whys []*Why) string {
+	_, rest, ok := SplitPrefix(content)
+	if !ok {
+		return content
+	}
+	return Prefix(whys) + " " + rest
+}
+
+// checkCodes returns an error unless every one of whys has a valid code
+// which no other has
+func checkCodes(whys []Why) error {
+	seen := make(map[string]string)
+	for _, why := range whys {
+		if err := ValidCode(why.Code); err != nil {
+			return fmt.Errorf("%s: %w", why.Name, err)
+		}
+		code := strings.ToLower(why.Code)
+		if other, ok := seen[code]; ok {
+			return fmt.Errorf("%s and %s both have the code %s", other, why.Name, why.Code)
+		}
+		seen[code] = why.Name
+	}
+	return nil
+}
+
+// rewritePrefixes rewrites the prefixes of the intentions linked to the goal
+// with id from the codes of the goals they are linked to, leaving that goal
+// out if it is being unlinked
+func rewritePrefixes(tx *gorm.DB, id uint, unlinking bool) error {
+	var intentions []Intention
+	err := tx.Preload("Whys", orderByNumber).
+		Where("id IN (SELECT intention_id FROM whys_intentions WHERE why_id = ?)", id).
+		Find(&intentions).Error
+	if err != nil {
+		return err
+	}
+	for _, i := range intentions {
+		var linked []*Why
+		for _, why := range i.Whys {
+			if !(unlinking && why.ID == id) {
+				linked = append(linked, why)
+			}
+		}
+		if err := updateContent(tx, i, linked); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateContent saves the intention's content with the prefix linking it to
+// whys, if that changes it
+func updateContent(tx *gorm.DB, i Intention, whys []*Why) error {
+	content := WithPrefix(i.Content, whys)
+	if content == i.Content {
+		return nil
+	}
+	return tx.Model(&Intention{}).Where("id = ?", i.ID).Update("content", content).Error
+}
+
+func orderByNumber(db *gorm.DB) *gorm.DB {
+	return db.Order("number, id")
+}
+
+// migrateCodes gives goals created before they had codes the number by which
+// they were shown, and rewrites the prefixes of all intentions from the goals
+// they are linked to, since those numbers were positions which changed as
+// goals were reordered and deleted
+func migrateCodes(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&Why{}).Where("code IS NULL OR code = ''").Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var whys []Why
+		if err := tx.Find(&whys).Error; err != nil {
+			return err
+		}
+		// active goals keep their numbers before archived ones do
+		sort.SliceStable(whys, func(i, j int) bool {
+			if whys[i].Archived != whys[j].Archived {
+				return !whys[i].Archived
+			}
+			return whys[i].Number < whys[j].Number
+		})
+		used := make(map[string]bool)
+		for _, why := range whys {
+			if why.Code != "" {
+				used[strings.ToLower(why.Code)] = true
+			}
+		}
+		for _, why := range whys {
+			if why.Code != "" {
+				continue
+			}
+			code := strconv.Itoa(why.Number)
+			if used[code] {
+				code = nextCode(used)
+			}
+			used[code] = true
+			if err := tx.Model(&Why{}).Where("id = ?", why.ID).Update("code", code).Error; err != nil {
+				return err
+			}
+		}
+
+		var intentions []Intention
+		if err := tx.Preload("Whys", orderByNumber).Find(&intentions).Error; err != nil {
+			return err
+		}
+		for _, i := range intentions {
+			if err := updateContent(tx, i, i.Whys); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,197 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidCode(t *testing.T) {
+	tests := []struct {
+		code string
+		ok   bool
+	}{
+		{"0", true},
+		{"w", true},
+		{"Q3", true},
+		{"abc", true},
+		{"", false},
+		{"abcd", false},
+		{"a-b", false},
+		{"a b", false},
+		{"é", false},
+		{"&", false},
+	}
+	for _, tt := range tests {
+		if err := ValidCode(tt.code); (err == nil) != tt.ok {
+			t.Errorf("ValidCode(%q) = %v, want ok %v", tt.code, err, tt.ok)
+		}
+	}
+}
+
+func TestNextCode(t *testing.T) {
+	tests := []struct {
+		codes []string
+		want  string
+	}{
+		{nil, "0"},
+		{[]string{"w", "h"}, "0"},
+		{[]string{"0", "1", "3"}, "2"},
+		{[]string{"1", "0", "w"}, "2"},
+	}
+	for _, tt := range tests {
+		var whys []Why
+		for _, code := range tt.codes {
+			whys = append(whys, Why{Code: code})
+		}
+		if got := NextCode(whys); got != tt.want {
+			t.Errorf("NextCode(%q) = %q, want %q", tt.codes, got, tt.want)
+		}
+	}
+}
+
+func TestSplitPrefix(t *testing.T) {
+	tests := []struct {
+		content string
+		codes   []string
+		rest    string
+		ok      bool
+	}{
+		{"0) write", []string{"0"}, "write", true},
+		{"0,w)  write (again)", []string{"0", "w"}, "write (again)", true},
+		{"&) call", nil, "call", true},
+		{"write (again)", nil, "write (again)", false},
+		{"call mum :)", nil, "call mum :)", false},
+		{"0, w) spaced", nil, "0, w) spaced", false},
+		{"no prefix", nil, "no prefix", false},
+	}
+	for _, tt := range tests {
+		codes, rest, ok := SplitPrefix(tt.content)
+		if !reflect.DeepEqual(codes, tt.codes) || rest != tt.rest || ok != tt.ok {
+			t.Errorf("SplitPrefix(%q) = %q, %q, %v, want %q, %q, %v",
+				tt.content, codes, rest, ok, tt.codes, tt.rest, tt.ok)
+		}
+	}
+}
+
+func TestWithPrefix(t *testing.T) {
+	work, health := &Why{Code: "w"}, &Why{Code: "h"}
+	tests := []struct {
+		content string
+		whys    []*Why
+		want    string
+	}{
+		{"0) write", []*Why{work}, "w) write"},
+		{"0) write", []*Why{work, health}, "w,h) write"},
+		{"w,h) write", nil, "&) write"},
+		{"&) call", []*Why{health}, "h) call"},
+		{"no prefix", []*Why{work}, "no prefix"},
+	}
+	for _, tt := range tests {
+		if got := WithPrefix(tt.content, tt.whys); got != tt.want {
+			t.Errorf("WithPrefix(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestCheckCodes(t *testing.T) {
+	tests := []struct {
+		codes []string
+		ok    bool
+	}{
+		{[]string{"0", "w", "h"}, true},
+		{[]string{"w", "W"}, false},
+		{[]string{"0", ""}, false},
+		{[]string{"toolong"}, false},
+	}
+	for _, tt := range tests {
+		var whys []Why
+		for _, code := range tt.codes {
+			whys = append(whys, Why{Name: "goal " + code, Code: code})
+		}
+		if err := checkCodes(whys); (err == nil) != tt.ok {
+			t.Errorf("checkCodes(%q) = %v, want ok %v", tt.codes, err, tt.ok)
+		}
+	}
+}
+
+func TestMigrateCodes(t *testing.T) {
+	store := newTestStore(t)
+	// numbered as goals were before they had codes, when the archived goal
+	// could share its number with an active one
+	whys := []Why{
+		{Name: "Work", Code: "a", Number: 0},
+		{Name: "Health", Code: "b", Number: 1},
+		{Name: "Old", Code: "c", Number: 0, Archived: true},
+	}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	day := date(t, "2026-10-15")
+	intentions := []Intention{
+		{Date: day, Content: "1) run", Whys: []*Why{&whys[1]}},
+		{Date: day, Content: "0) old", Whys: []*Why{&whys[2]}},
+		{Date: day, Content: "0,1) both", Whys: []*Why{&whys[0], &whys[1]}},
+	}
+	if err := store.UpsertIntentions(intentions); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.db.Exec("UPDATE whys SET code = ''").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateCodes(store.db); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetWhys(All)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := make(map[string]string)
+	for _, why := range got {
+		codes[why.Name] = why.Code
+	}
+	if want := map[string]string{"Work": "0", "Health": "1", "Old": "2"}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("codes = %v, want %v", codes, want)
+	}
+	saved, err := store.GetDaysIntentions(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents []string
+	for _, i := range saved {
+		contents = append(contents, i.Content)
+	}
+	if want := []string{"1) run", "2) old", "0,1) both"}; !reflect.DeepEqual(contents, want) {
+		t.Errorf("contents = %q, want %q", contents, want)
+	}
+}
+
+func TestUpsertWhysRewritesPrefixes(t *testing.T) {
+	store := newTestStore(t)
+	whys := []Why{{Name: "Work", Code: "w"}, {Name: "Health", Code: "h", Number: 1}}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	day := date(t, "2026-10-15")
+	intentions := []Intention{{Date: day, Content: "w,h) walk to work", Whys: []*Why{&whys[0], &whys[1]}}}
+	if err := store.UpsertIntentions(intentions); err != nil {
+		t.Fatal(err)
+	}
+
+	whys[0].Code = "job"
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.GetDaysIntentions(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Content != "job,h) walk to work" {
+		t.Errorf("got %+v, want the prefix rewritten to job,h)", saved)
+	}
+
+	whys[1].Code = "JOB"
+	if err := store.UpsertWhys(whys); err == nil {
+		t.Error("UpsertWhys accepted two goals with the same code")
+	}
+}
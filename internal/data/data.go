@@ -1,7 +1,11 @@
 package data
 
 import (
-	"log"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/adrg/xdg"
@@ -22,10 +26,23 @@ type Why struct {
 	Name        string
 	Description string
 
+	// Code links intentions to the goal in their prefix, and is kept when
+	// goals are reordered. Number is the goal's position when listed.
+	Code     string
 	Number   int
 	Color    lipgloss.Color
 	Archived bool
 
+	// ParentID is the goal which this is a sub-goal of, if any
+	ParentID *uint
+
+	// StartDate and TargetDate optionally bound when the goal is worked
+	// towards, and Target is an optional amount of Measure to reach
+	StartDate  *time.Time
+	TargetDate *time.Time
+	Target     int
+	Measure    MeasureEnum
+
 	Intentions []*Intention `gorm:"many2many:whys_intentions;"`
 }
 
@@ -50,23 +67,81 @@ type Intention struct {
 	Position int
 
 	Pomos int
+	// Pomodoros started on the intention but stopped before they finished
+	Interruptions int
+
+	// True for intentions planned ahead for a future day, until they are
+	// confirmed on that day's input page
+	Planned bool
+
+	// CarriedFromID links an unfinished intention carried over from a previous
+	// day to the intention it was first written as
+	CarriedFromID *uint
+	CarriedFrom   *Intention
+	// RecurringID links an intention to the recurring intention it was
+	// created from
+	RecurringID *uint
 
 	Whys []*Why `gorm:"many2many:whys_intentions;"`
 }
 
-func NewStore() Store {
-	dataFilePath, err := xdg.DataFile("goalie/goalie.db")
+// Postponed returns the number of days the intention has been carried over
+// since it was first written. CarriedFrom must be loaded.
+func (i Intention) Postponed() int {
+	if i.CarriedFrom == nil {
+		return 0
+	}
+	return int(i.Date.Sub(i.CarriedFrom.Date).Hours()/24 + 0.5)
+}
+
+// DBEnv is the environment variable which may hold the path of the database
+const DBEnv = "GOALIE_DB"
+
+// Path returns the location of the database to use. Options given on the
+// command line win over the environment: an explicit path takes precedence
+// over the path for the named profile, which in turn takes precedence over
+// the one in the GOALIE_DB environment variable. Without any of them, the
+// default database in the user's data directory is used.
+func Path(explicit, profile string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	if profile != "" {
+		if !profileRegex.MatchString(profile) {
+			return "", fmt.Errorf("invalid profile name %q: only letters, digits, '-' and '_' are allowed", profile)
+		}
+		return xdg.DataFile(filepath.Join("goalie", "profiles", profile+".db"))
+	}
+	if env := os.Getenv(DBEnv); env != "" {
+		return env, nil
+	}
+	return xdg.DataFile("goalie/goalie.db")
+}
+
+var profileRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// NewStore opens the database at path, creating it if necessary
+func NewStore(path string) (Store, error) {
+	db, err := gorm.Open(sqlite.Open(path))
 	if err != nil {
-		log.Fatalf("Could not find datafile path: %v", err)
+		return Store{}, fmt.Errorf("error opening database %s: %w", path, err)
 	}
-	db, err := gorm.Open(sqlite.Open(dataFilePath))
+	err = db.AutoMigrate(&Why{}, &Intention{}, &Day{}, &Review{}, &PomoSession{}, &RecurringIntention{}, &Setting{})
 	if err != nil {
-		log.Fatalf("Error opening database: %v", err)
+		return Store{}, fmt.Errorf("error migrating database %s: %w", path, err)
+	}
+	if err := migrateDates(db); err != nil {
+		return Store{}, fmt.Errorf("error migrating database %s: %w", path, err)
+	}
+	if err := migrateCodes(db); err != nil {
+		return Store{}, fmt.Errorf("error migrating database %s: %w", path, err)
+	}
+	if err := migrateWeeks(db); err != nil {
+		return Store{}, fmt.Errorf("error migrating database %s: %w", path, err)
 	}
-	db.AutoMigrate(&Why{}, &Intention{}, &Day{})
 	return Store{
 		db: db,
-	}
+	}, nil
 }
 
 type Store struct {
@@ -95,23 +170,110 @@ func (s *Store) GetWhys(status WhyStatusEnum) ([]Why, error) {
 	return result, err
 }
 
+// UpsertWhys saves goals, giving those without a code the lowest unused
+// number. Changing a goal's code rewrites the prefixes of its intentions.
 func (s *Store) UpsertWhys(items []Why) error {
-	err := s.db.Clauses(clause.OnConflict{
-		UpdateAll: true,
-	}).Create(&items).Error
+	for i := range items {
+		for _, date := range []**time.Time{&items[i].StartDate, &items[i].TargetDate} {
+			if *date != nil {
+				d := DateOf(**date)
+				*date = &d
+			}
+		}
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []Why
+		if err := tx.Find(&existing).Error; err != nil {
+			return err
+		}
+		// the goals as they will be once items are saved, whose codes must
+		// all differ
+		saved := make(map[uint]Why)
+		oldCodes := make(map[uint]string)
+		for _, why := range existing {
+			saved[why.ID] = why
+			oldCodes[why.ID] = why.Code
+		}
+		used := make(map[string]bool)
+		for _, why := range items {
+			if why.ID != 0 {
+				saved[why.ID] = why
+			}
+		}
+		for _, why := range saved {
+			used[strings.ToLower(why.Code)] = true
+		}
+		for _, why := range items {
+			used[strings.ToLower(why.Code)] = true
+		}
+		var all []Why
+		for i := range items {
+			if items[i].Code == "" {
+				items[i].Code = nextCode(used)
+				used[items[i].Code] = true
+			}
+			if items[i].ID == 0 {
+				all = append(all, items[i])
+			} else {
+				saved[items[i].ID] = items[i]
+			}
+		}
+		for _, why := range saved {
+			all = append(all, why)
+		}
+		if err := checkCodes(all); err != nil {
+			return err
+		}
+
+		err := tx.Clauses(clause.OnConflict{
+			UpdateAll: true,
+		}).Create(&items).Error
+		if err != nil {
+			return err
+		}
+		for _, why := range items {
+			if code, ok := oldCodes[why.ID]; ok && code != why.Code {
+				if err := rewritePrefixes(tx, why.ID, false); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
 	return err
 }
 
+// DeleteWhys deletes goals along with their reviews and links to intentions,
+// which are kept without them, and moves their remaining sub-goals up a level.
+// Goals which should keep their history are archived instead.
 func (s *Store) DeleteWhys(whys []Why) error {
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		for _, why := range whys {
-			var err error
-			if why.ID > 0 {
-				err = s.db.Delete(&why).Error
+			if why.ID == 0 {
+				continue
 			}
+			if err := rewritePrefixes(tx, why.ID, true); err != nil {
+				return err
+			}
+			// sub-goals which weren't deleted with the goal are moved up
+			// to its parent
+			err := tx.Model(&Why{}).Where("parent_id = ?", why.ID).
+				Update("parent_id", gorm.Expr("(SELECT parent_id FROM whys WHERE id = ?)", why.ID)).Error
 			if err != nil {
 				return err
 			}
+			if err := tx.Exec("DELETE FROM whys_recurring_intentions WHERE why_id = ?", why.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("why_id = ?", why.ID).Delete(&Day{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("why_id = ?", why.ID).Delete(&Review{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Select("Intentions").Delete(&why).Error; err != nil {
+				return err
+			}
 		}
 		return nil
 	})
@@ -119,6 +281,9 @@ func (s *Store) DeleteWhys(whys []Why) error {
 }
 
 func (s *Store) UpsertIntentions(items []Intention) error {
+	for i := range items {
+		items[i].Date = DateOf(items[i].Date)
+	}
 	err := s.db.Clauses(clause.OnConflict{
 		UpdateAll: true,
 	}).Create(&items).Error
@@ -127,7 +292,29 @@ func (s *Store) UpsertIntentions(items []Intention) error {
 
 func (s *Store) GetDaysIntentions(day time.Time) ([]Intention, error) {
 	var results []Intention
-	err := s.db.Model(&Intention{}).Preload("Whys").Where("date = ?", day).Find(&results).Error
+	err := s.db.Model(&Intention{}).Preload("Whys").Where("date = ?", DateOf(day)).Find(&results).Error
+	return results, err
+}
+
+// DeleteIntentions deletes intentions along with their links to goals
+func (s *Store) DeleteIntentions(items []Intention) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return s.db.Select("Whys").Delete(&items).Error
+}
+
+// GetIntentionsBetween returns the intentions dated from start up to and
+// including end, ordered by date and position, with the intentions they were
+// carried over from. Intentions planned ahead aren't a day's own until they
+// are confirmed, so those which haven't been are left out, as they are from
+// progress, statistics, reviews and exports.
+func (s *Store) GetIntentionsBetween(start, end time.Time) ([]Intention, error) {
+	var results []Intention
+	err := s.db.Model(&Intention{}).Preload("Whys").Preload("CarriedFrom").
+		Where("date >= ? AND date <= ? AND planned = 0", DateOf(start), DateOf(end)).
+		Order("date, position").
+		Find(&results).Error
 	return results, err
 }
 
@@ -141,9 +328,259 @@ type Day struct {
 	Reflection string
 }
 
+// UpsertDayReview saves days, replacing any review already given for the same
+// goal on the same date, so that a day can be reviewed again
 func (s *Store) UpsertDayReview(days []Day) error {
-	err := s.db.Clauses(clause.OnConflict{
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range days {
+			days[i].Date = DateOf(days[i].Date)
+			day := days[i]
+			whyID := day.WhyID
+			if day.Why.ID != 0 {
+				whyID = day.Why.ID
+			}
+			if err := tx.Where("date = ? AND why_id = ?", day.Date, whyID).
+				Delete(&Day{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			UpdateAll: true,
+		}).Create(&days).Error
+	})
+}
+
+// GetDayReviewsBetween returns the day reviews dated from start up to and
+// including end, ordered by date.
+func (s *Store) GetDayReviewsBetween(start, end time.Time) ([]Day, error) {
+	var results []Day
+	err := s.db.Model(&Day{}).Preload("Why").
+		Where("date >= ? AND date <= ?", DateOf(start), DateOf(end)).
+		Order("date").
+		Find(&results).Error
+	return results, err
+}
+
+// Period is the length of time covered by a review
+type Period int
+
+const (
+	Weekly Period = iota
+	Monthly
+	Quarterly
+	Yearly
+)
+
+func (p Period) String() string {
+	switch p {
+	case Weekly:
+		return "weekly"
+	case Monthly:
+		return "monthly"
+	case Quarterly:
+		return "quarterly"
+	case Yearly:
+		return "yearly"
+	}
+	return "unknown"
+}
+
+// Sub returns the period that reviews of this period roll up. Weekly reviews
+// have no sub-period, so ok is false for them.
+func (p Period) Sub() (sub Period, ok bool) {
+	if p == Weekly {
+		return Weekly, false
+	}
+	return p - 1, true
+}
+
+// Bounds returns the first and last days of the period containing day. Weeks
+// run from Monday to Sunday, so that a week's reviews are found on any of its
+// days.
+func (p Period) Bounds(day time.Time) (start, end time.Time) {
+	year, month, date := day.Date()
+	loc := day.Location()
+	switch p {
+	case Monthly:
+		start = time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 1, -1)
+	case Quarterly:
+		start = time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 3, -1)
+	case Yearly:
+		start = time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(1, 0, -1)
+	default:
+		// days since Monday, which is 1 in time.Weekday
+		offset := (int(day.Weekday()) + 6) % 7
+		start = time.Date(year, month, date-offset, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 0, 6)
+	}
+	return start, end
+}
+
+// Prev returns the bounds of the period immediately before the one starting
+// on start
+func (p Period) Prev(start time.Time) (time.Time, time.Time) {
+	return p.Bounds(start.AddDate(0, 0, -1))
+}
+
+// Next returns the bounds of the period immediately after the one ending on
+// end
+func (p Period) Next(end time.Time) (time.Time, time.Time) {
+	return p.Bounds(end.AddDate(0, 0, 1))
+}
+
+// migrateWeeks moves weekly reviews saved when weeks ended on the day they
+// were reviewed to the week from Monday to Sunday containing their last day
+func migrateWeeks(db *gorm.DB) error {
+	var reviews []Review
+	if err := db.Where("period = ?", Weekly).Find(&reviews).Error; err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, review := range reviews {
+			start, end := Weekly.Bounds(DateOf(review.End))
+			if start.Equal(DateOf(review.Start)) {
+				continue
+			}
+			err := tx.Model(&Review{}).Where("id = ?", review.ID).
+				Updates(map[string]any{"start": start, "end": end}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Review holds the answers to the periodic review questions for one goal.
+// Reviews with a WhyID of 0 hold overall remarks for the period.
+type Review struct {
+	ID        uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// The review covers every day from Start up to and including End
+	Period Period
+	Start  time.Time
+	End    time.Time
+
+	WhyID uint
+	Why   Why
+
+	Progress  string
+	Obstacles string
+	Next      string
+}
+
+func (s *Store) UpsertReviews(reviews []Review) error {
+	for i := range reviews {
+		reviews[i].Start = DateOf(reviews[i].Start)
+		reviews[i].End = DateOf(reviews[i].End)
+	}
+	// Goals are only ever modified through UpsertWhys
+	err := s.db.Omit(clause.Associations).Clauses(clause.OnConflict{
 		UpdateAll: true,
-	}).Create(&days).Error
+	}).Create(&reviews).Error
 	return err
 }
+
+// GetReviews returns the reviews of the given period beginning on start
+func (s *Store) GetReviews(period Period, start time.Time) ([]Review, error) {
+	var results []Review
+	err := s.db.Model(&Review{}).Preload("Why").
+		Where("period = ? AND start = ?", period, DateOf(start)).
+		Find(&results).Error
+	return results, err
+}
+
+// GetSubReviews returns the reviews rolled up by a review of the given period
+// covering start to end, that is, those of its sub-period which end within it.
+func (s *Store) GetSubReviews(period Period, start, end time.Time) ([]Review, error) {
+	var results []Review
+	sub, ok := period.Sub()
+	if !ok {
+		return results, nil
+	}
+	err := s.db.Model(&Review{}).Preload("Why").
+		Where("period = ? AND \"end\" >= ? AND \"end\" <= ?", sub, DateOf(start), DateOf(end)).
+		Order("start").
+		Find(&results).Error
+	return results, err
+}
+
+// Summary aggregates the intentions and day reviews belonging to one goal
+type Summary struct {
+	Intentions int
+	Done       int
+	Cancelled  int
+	Unintended int
+	Pomos      int
+
+	// Reviewed is the number of days with a day review, and Enough the number
+	// of those on which the user felt they did enough
+	Reviewed int
+	Enough   int
+
+	// Reflections holds the day reviews with a non-empty reflection
+	Reflections []Day
+}
+
+// CompletionRate is the fraction of intentions that were not cancelled which
+// were done
+func (s Summary) CompletionRate() float64 {
+	if s.Intentions-s.Cancelled <= 0 {
+		return 0
+	}
+	return float64(s.Done) / float64(s.Intentions-s.Cancelled)
+}
+
+// Summarize groups intentions and day reviews by the ID of the goal they are
+// associated with. Intentions and day reviews without a goal are grouped under
+// 0, and intentions with several goals are counted towards each of them.
+func Summarize(intentions []Intention, days []Day) map[uint]*Summary {
+	result := make(map[uint]*Summary)
+	get := func(id uint) *Summary {
+		if result[id] == nil {
+			result[id] = &Summary{}
+		}
+		return result[id]
+	}
+
+	for _, intention := range intentions {
+		ids := []uint{0}
+		if len(intention.Whys) > 0 {
+			ids = ids[:0]
+			for _, why := range intention.Whys {
+				ids = append(ids, why.ID)
+			}
+		}
+		for _, id := range ids {
+			summary := get(id)
+			summary.Intentions++
+			summary.Pomos += intention.Pomos
+			if intention.Done {
+				summary.Done++
+			}
+			if intention.Cancelled {
+				summary.Cancelled++
+			}
+			if intention.Unintended {
+				summary.Unintended++
+			}
+		}
+	}
+
+	for _, day := range days {
+		summary := get(day.WhyID)
+		summary.Reviewed++
+		if day.Enough {
+			summary.Enough++
+		}
+		if day.Reflection != "" {
+			summary.Reflections = append(summary.Reflections, day)
+		}
+	}
+	return result
+}
@@ -0,0 +1,254 @@
+package data
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// newTestStore opens an empty store in a temporary directory
+func newTestStore(t *testing.T) Store {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "goalie.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// date returns midnight UTC on the day written as YYYY-MM-DD
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestPeriodBounds(t *testing.T) {
+	tests := []struct {
+		period     Period
+		day        string
+		start, end string
+	}{
+		// 2026-10-16 is a Friday
+		{Weekly, "2026-10-16", "2026-10-12", "2026-10-18"},
+		{Weekly, "2026-10-12", "2026-10-12", "2026-10-18"},
+		{Weekly, "2026-10-18", "2026-10-12", "2026-10-18"},
+		{Weekly, "2026-01-01", "2025-12-29", "2026-01-04"},
+		{Monthly, "2026-02-14", "2026-02-01", "2026-02-28"},
+		{Monthly, "2024-02-29", "2024-02-01", "2024-02-29"},
+		{Quarterly, "2026-08-31", "2026-07-01", "2026-09-30"},
+		{Quarterly, "2026-01-01", "2026-01-01", "2026-03-31"},
+		{Yearly, "2026-10-16", "2026-01-01", "2026-12-31"},
+	}
+	for _, tt := range tests {
+		start, end := tt.period.Bounds(date(t, tt.day))
+		if !start.Equal(date(t, tt.start)) || !end.Equal(date(t, tt.end)) {
+			t.Errorf("%s.Bounds(%s) = %s, %s, want %s, %s", tt.period, tt.day,
+				start.Format("2006-01-02"), end.Format("2006-01-02"), tt.start, tt.end)
+		}
+	}
+}
+
+func TestPeriodPrevNext(t *testing.T) {
+	tests := []struct {
+		period     Period
+		day        string
+		prev, next string
+	}{
+		{Weekly, "2026-10-16", "2026-10-05", "2026-10-19"},
+		{Monthly, "2026-03-31", "2026-02-01", "2026-04-01"},
+		{Quarterly, "2026-01-15", "2025-10-01", "2026-04-01"},
+		{Yearly, "2026-06-01", "2025-01-01", "2027-01-01"},
+	}
+	for _, tt := range tests {
+		start, end := tt.period.Bounds(date(t, tt.day))
+		prevStart, prevEnd := tt.period.Prev(start)
+		if !prevStart.Equal(date(t, tt.prev)) || !prevEnd.AddDate(0, 0, 1).Equal(start) {
+			t.Errorf("%s.Prev(%s) = %s, %s", tt.period, start.Format("2006-01-02"),
+				prevStart.Format("2006-01-02"), prevEnd.Format("2006-01-02"))
+		}
+		nextStart, _ := tt.period.Next(end)
+		if !nextStart.Equal(date(t, tt.next)) || !end.AddDate(0, 0, 1).Equal(nextStart) {
+			t.Errorf("%s.Next(%s) starts %s, want %s", tt.period, end.Format("2006-01-02"),
+				nextStart.Format("2006-01-02"), tt.next)
+		}
+	}
+}
+
+func TestPeriodSub(t *testing.T) {
+	tests := []struct {
+		period Period
+		sub    Period
+		ok     bool
+	}{
+		{Weekly, Weekly, false},
+		{Monthly, Weekly, true},
+		{Quarterly, Monthly, true},
+		{Yearly, Quarterly, true},
+	}
+	for _, tt := range tests {
+		if sub, ok := tt.period.Sub(); sub != tt.sub || ok != tt.ok {
+			t.Errorf("%s.Sub() = %s, %v, want %s, %v", tt.period, sub, ok, tt.sub, tt.ok)
+		}
+	}
+}
+
+func TestGetSubReviews(t *testing.T) {
+	store := newTestStore(t)
+	whys := []Why{{Name: "Work", Code: "w"}}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	var reviews []Review
+	// weeks ending in October 2026, and those either side of them, which
+	// end in September and November
+	for _, day := range []string{"2026-10-01", "2026-10-05", "2026-10-26", "2026-09-25"} {
+		start, end := Weekly.Bounds(date(t, day))
+		reviews = append(reviews, Review{Period: Weekly, Start: start, End: end, WhyID: whys[0].ID, Progress: day})
+	}
+	start, end := Monthly.Bounds(date(t, "2026-10-15"))
+	reviews = append(reviews, Review{Period: Monthly, Start: start, End: end, WhyID: whys[0].ID, Progress: "october"})
+	if err := store.UpsertReviews(reviews); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.GetSubReviews(Monthly, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progress []string
+	for _, r := range got {
+		progress = append(progress, r.Progress)
+	}
+	// the week from September 28 ends in October, so it is rolled up by it,
+	// but the one from October 26 isn't
+	if want := []string{"2026-10-01", "2026-10-05"}; !reflect.DeepEqual(progress, want) {
+		t.Errorf("got weekly reviews %q, want %q", progress, want)
+	}
+	if len(got) > 0 && got[0].Why.Name != "Work" {
+		t.Errorf("got %+v, want the goal read with the review", got[0])
+	}
+
+	monthly, err := store.GetReviews(Monthly, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monthly) != 1 || monthly[0].Progress != "october" {
+		t.Errorf("got monthly reviews %+v, want october's", monthly)
+	}
+	if got, err := store.GetSubReviews(Weekly, start, end); err != nil || len(got) != 0 {
+		t.Errorf("GetSubReviews(weekly) = %+v, %v, want nothing", got, err)
+	}
+}
+
+func TestMigrateWeeks(t *testing.T) {
+	store := newTestStore(t)
+	// a week ending on the Friday it was reviewed
+	review := Review{Period: Weekly, Start: date(t, "2026-10-10"), End: date(t, "2026-10-16")}
+	if err := store.UpsertReviews([]Review{review}); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateWeeks(store.db); err != nil {
+		t.Fatal(err)
+	}
+	reviews, err := store.GetReviews(Weekly, date(t, "2026-10-12"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reviews) != 1 || !reviews[0].End.Equal(date(t, "2026-10-18")) {
+		t.Errorf("got %+v, want the review moved to the week of 2026-10-12", reviews)
+	}
+}
+
+func TestPath(t *testing.T) {
+	// cleanups run last first, so the directories are read again once the
+	// environment is restored
+	t.Cleanup(xdg.Reload)
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	xdg.Reload()
+	profileDB := filepath.Join(dataHome, "goalie", "profiles", "work.db")
+	defaultDB := filepath.Join(dataHome, "goalie", "goalie.db")
+
+	tests := []struct {
+		explicit, profile, env string
+		want                   string
+	}{
+		{"/tmp/a.db", "work", "/tmp/env.db", "/tmp/a.db"},
+		{"", "work", "/tmp/env.db", profileDB},
+		{"", "", "/tmp/env.db", "/tmp/env.db"},
+		{"", "", "", defaultDB},
+	}
+	for _, tt := range tests {
+		t.Setenv(DBEnv, tt.env)
+		got, err := Path(tt.explicit, tt.profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Path(%q, %q) with %s=%q = %q, want %q", tt.explicit, tt.profile, DBEnv, tt.env, got, tt.want)
+		}
+	}
+	if _, err := Path("", "../work"); err == nil {
+		t.Error("Path accepted an invalid profile name")
+	}
+}
+
+func TestGetIntentionsBetween(t *testing.T) {
+	store := newTestStore(t)
+	day := date(t, "2026-10-15")
+	intentions := []Intention{
+		{Date: day, Content: "&) second", Position: 1},
+		{Date: day, Content: "&) first"},
+		{Date: day, Content: "&) planned", Position: 2, Planned: true},
+		{Date: day.AddDate(0, 0, 1), Content: "&) next day"},
+		{Date: day.AddDate(0, 0, 2), Content: "&) too late"},
+	}
+	if err := store.UpsertIntentions(intentions); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetIntentionsBetween(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents []string
+	for _, i := range got {
+		contents = append(contents, i.Content)
+	}
+	if want := []string{"&) first", "&) second", "&) next day"}; !reflect.DeepEqual(contents, want) {
+		t.Errorf("got %q, want %q", contents, want)
+	}
+}
+
+func TestGetDayReviewsBetween(t *testing.T) {
+	store := newTestStore(t)
+	whys := []Why{{Name: "Work", Code: "w"}}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	day := date(t, "2026-10-15")
+	days := []Day{
+		{Date: day.AddDate(0, 0, 1), WhyID: whys[0].ID, Reflection: "second"},
+		{Date: day, WhyID: whys[0].ID, Enough: true, Reflection: "first"},
+		{Date: day.AddDate(0, 0, -1), WhyID: whys[0].ID, Reflection: "too early"},
+	}
+	if err := store.UpsertDayReview(days); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.GetDayReviewsBetween(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Reflection != "first" || got[1].Reflection != "second" {
+		t.Fatalf("got %+v, want the reflections on the 15th and 16th in order", got)
+	}
+	if !got[0].Enough || got[0].Why.Name != "Work" {
+		t.Errorf("got %+v, want enough given for Work", got[0])
+	}
+}
@@ -0,0 +1,687 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExportVersion is the version of the document produced by Export. It must be
+// incremented whenever the document changes in a way older versions of goalie
+// couldn't import, which refuse documents of versions newer than theirs
+// rather than silently dropping what they don't know.
+//
+// Version 1 has goals, intentions, day reviews, periodic reviews and
+// pomodoro sessions. Version 2 adds recurring intentions, planned and carried
+// over intentions, and goals' codes, parents, start and target dates and
+// targets.
+const ExportVersion = 2
+
+// dateLayout is the format of dates in exported documents. Days are calendar
+// dates, so they are exported without a time or zone to be imported as the
+// same day wherever the import happens.
+const dateLayout = "2006-01-02"
+
+// Export is a complete copy of a store. IDs in it are only meaningful within
+// the document and are remapped when it is imported.
+type Export struct {
+	Version    int               `json:"version"`
+	Exported   time.Time         `json:"exported"`
+	Whys       []ExportWhy       `json:"whys"`
+	Intentions []ExportIntention `json:"intentions"`
+	Days       []ExportDay       `json:"days"`
+	Reviews    []ExportReview    `json:"reviews"`
+	Sessions   []ExportSession   `json:"sessions"`
+	Recurring  []ExportRecurring `json:"recurring"`
+}
+
+type ExportWhy struct {
+	ID          uint      `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Code        string    `json:"code"`
+	Number      int       `json:"number"`
+	Color       string    `json:"color"`
+	Archived    bool      `json:"archived"`
+	// ParentID is the ID of the goal which this is a sub-goal of
+	ParentID   *uint  `json:"parent_id,omitempty"`
+	StartDate  string `json:"start_date,omitempty"`
+	TargetDate string `json:"target_date,omitempty"`
+	// Target is an amount like "50 pomos", as read by ParseTarget
+	Target string `json:"target,omitempty"`
+}
+
+type ExportIntention struct {
+	ID            uint   `json:"id"`
+	Date          string `json:"date"`
+	Content       string `json:"content"`
+	Done          bool   `json:"done"`
+	Cancelled     bool   `json:"cancelled"`
+	Outcome       bool   `json:"outcome"`
+	Unintended    bool   `json:"unintended"`
+	Position      int    `json:"position"`
+	Pomos         int    `json:"pomos"`
+	Interruptions int    `json:"interruptions"`
+	Planned       bool   `json:"planned,omitempty"`
+	// WhyIDs links the intention to the whys with these IDs
+	WhyIDs []uint `json:"why_ids"`
+	// CarriedFromID is the ID of the intention this one was carried over from
+	CarriedFromID *uint `json:"carried_from_id,omitempty"`
+	// RecurringID is the ID of the recurring intention this one was created from
+	RecurringID *uint `json:"recurring_id,omitempty"`
+}
+
+type ExportDay struct {
+	Date       string `json:"date"`
+	WhyID      uint   `json:"why_id"`
+	Enough     bool   `json:"enough"`
+	Reflection string `json:"reflection"`
+}
+
+type ExportReview struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Period    string    `json:"period"`
+	Start     string    `json:"start"`
+	End       string    `json:"end"`
+	WhyID     uint      `json:"why_id"`
+	Progress  string    `json:"progress"`
+	Obstacles string    `json:"obstacles"`
+	Next      string    `json:"next"`
+}
+
+type ExportRecurring struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Content   string    `json:"content"`
+	// Schedule is written as accepted by ParseSchedule
+	Schedule string `json:"schedule"`
+	Start    string `json:"start"`
+	WhyIDs   []uint `json:"why_ids"`
+}
+
+type ExportSession struct {
+	IntentionID uint          `json:"intention_id"`
+	Start       time.Time     `json:"start"`
+	End         time.Time     `json:"end"`
+	Duration    time.Duration `json:"duration"`
+	Completed   bool          `json:"completed"`
+}
+
+// Export copies every goal, intention, day review, periodic review and
+// pomodoro session in the store into a document
+func (s *Store) Export() (Export, error) {
+	doc := Export{
+		Version:    ExportVersion,
+		Exported:   time.Now(),
+		Whys:       []ExportWhy{},
+		Intentions: []ExportIntention{},
+		Days:       []ExportDay{},
+		Reviews:    []ExportReview{},
+		Sessions:   []ExportSession{},
+		Recurring:  []ExportRecurring{},
+	}
+
+	var whys []Why
+	if err := s.db.Order("id").Find(&whys).Error; err != nil {
+		return doc, err
+	}
+	for _, why := range whys {
+		doc.Whys = append(doc.Whys, ExportWhy{
+			ID:          why.ID,
+			CreatedAt:   why.CreatedAt,
+			Name:        why.Name,
+			Description: why.Description,
+			Code:        why.Code,
+			Number:      why.Number,
+			Color:       string(why.Color),
+			Archived:    why.Archived,
+			ParentID:    why.ParentID,
+			StartDate:   formatOptionalDate(why.StartDate),
+			TargetDate:  formatOptionalDate(why.TargetDate),
+			Target:      FormatTarget(why),
+		})
+	}
+
+	var intentions []Intention
+	if err := s.db.Preload("Whys").Order("date, position").Find(&intentions).Error; err != nil {
+		return doc, err
+	}
+	for _, i := range intentions {
+		ids := []uint{}
+		for _, why := range i.Whys {
+			ids = append(ids, why.ID)
+		}
+		doc.Intentions = append(doc.Intentions, ExportIntention{
+			ID:            i.ID,
+			Date:          i.Date.Format(dateLayout),
+			Content:       i.Content,
+			Done:          i.Done,
+			Cancelled:     i.Cancelled,
+			Outcome:       i.Outcome,
+			Unintended:    i.Unintended,
+			Position:      i.Position,
+			Pomos:         i.Pomos,
+			Interruptions: i.Interruptions,
+			Planned:       i.Planned,
+			WhyIDs:        ids,
+			CarriedFromID: i.CarriedFromID,
+			RecurringID:   i.RecurringID,
+		})
+	}
+
+	var days []Day
+	if err := s.db.Order("date, why_id").Find(&days).Error; err != nil {
+		return doc, err
+	}
+	for _, day := range days {
+		doc.Days = append(doc.Days, ExportDay{
+			Date:       day.Date.Format(dateLayout),
+			WhyID:      day.WhyID,
+			Enough:     day.Enough,
+			Reflection: day.Reflection,
+		})
+	}
+
+	var reviews []Review
+	if err := s.db.Order("start, period, why_id").Find(&reviews).Error; err != nil {
+		return doc, err
+	}
+	for _, review := range reviews {
+		doc.Reviews = append(doc.Reviews, ExportReview{
+			CreatedAt: review.CreatedAt,
+			UpdatedAt: review.UpdatedAt,
+			Period:    review.Period.String(),
+			Start:     review.Start.Format(dateLayout),
+			End:       review.End.Format(dateLayout),
+			WhyID:     review.WhyID,
+			Progress:  review.Progress,
+			Obstacles: review.Obstacles,
+			Next:      review.Next,
+		})
+	}
+
+	var sessions []PomoSession
+	if err := s.db.Order("start").Find(&sessions).Error; err != nil {
+		return doc, err
+	}
+	for _, session := range sessions {
+		doc.Sessions = append(doc.Sessions, ExportSession{
+			IntentionID: session.IntentionID,
+			Start:       session.Start,
+			End:         session.End,
+			Duration:    session.Duration,
+			Completed:   session.Completed,
+		})
+	}
+
+	var recurring []RecurringIntention
+	if err := s.db.Preload("Whys").Order("id").Find(&recurring).Error; err != nil {
+		return doc, err
+	}
+	for _, r := range recurring {
+		ids := []uint{}
+		for _, why := range r.Whys {
+			ids = append(ids, why.ID)
+		}
+		doc.Recurring = append(doc.Recurring, ExportRecurring{
+			ID:        r.ID,
+			CreatedAt: r.CreatedAt,
+			Content:   r.Content,
+			Schedule:  r.Schedule.String(),
+			Start:     r.Schedule.Start.Format(dateLayout),
+			WhyIDs:    ids,
+		})
+	}
+	return doc, nil
+}
+
+// ImportStats counts the rows added by an import, and those skipped because
+// the store already had them
+type ImportStats struct {
+	Added   map[string]int
+	Skipped map[string]int
+}
+
+// Import adds the contents of doc to the store, giving them new IDs. Rows
+// which duplicate ones already in the store are skipped: goals with the same
+// name, intentions with the same date and content, day reviews and periodic
+// reviews of the same goal and dates, sessions of the same intention starting
+// at the same time, and recurring intentions with the same content and
+// schedule. Nothing is imported if any of doc is invalid.
+// With dryRun, the stats of the import are returned but nothing is saved.
+func (s *Store) Import(doc Export, dryRun bool) (ImportStats, error) {
+	stats := ImportStats{Added: map[string]int{}, Skipped: map[string]int{}}
+	if doc.Version < 1 || doc.Version > ExportVersion {
+		return stats, fmt.Errorf("unsupported export version %d, this version of goalie reads up to version %d", doc.Version, ExportVersion)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		whyIDs, err := importWhys(tx, doc.Whys, stats)
+		if err != nil {
+			return err
+		}
+		recurringIDs, err := importRecurring(tx, doc.Recurring, whyIDs, stats)
+		if err != nil {
+			return err
+		}
+		intentionIDs, err := importIntentions(tx, doc.Intentions, whyIDs, recurringIDs, stats)
+		if err != nil {
+			return err
+		}
+		if err := importDays(tx, doc.Days, whyIDs, stats); err != nil {
+			return err
+		}
+		if err := importReviews(tx, doc.Reviews, whyIDs, stats); err != nil {
+			return err
+		}
+		if err := importSessions(tx, doc.Sessions, intentionIDs, stats); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return stats, err
+}
+
+// errDryRun rolls back the transaction of a dry run import
+var errDryRun = errors.New("dry run")
+
+// importWhys returns a map from the IDs of whys in the document to their IDs
+// in the store
+func importWhys(tx *gorm.DB, whys []ExportWhy, stats ImportStats) (map[uint]uint, error) {
+	ids := make(map[uint]uint)
+	var existing []Why
+	if err := tx.Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]uint)
+	used := make(map[string]bool)
+	next := 0
+	for _, why := range existing {
+		byName[why.Name] = why.ID
+		used[strings.ToLower(why.Code)] = true
+		if why.Number >= next {
+			next = why.Number + 1
+		}
+	}
+
+	// New goals are numbered after the existing ones, keeping their order,
+	// and keep their codes unless another goal has them
+	sorted := append([]ExportWhy(nil), whys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Number < sorted[j].Number
+	})
+	var added []ExportWhy
+	for _, w := range sorted {
+		if id, ok := byName[w.Name]; ok {
+			ids[w.ID] = id
+			stats.Skipped["whys"]++
+			continue
+		}
+		code := w.Code
+		if ValidCode(code) != nil || used[strings.ToLower(code)] {
+			code = nextCode(used)
+		}
+		used[strings.ToLower(code)] = true
+		target, measure, err := ParseTarget(w.Target)
+		if err != nil {
+			return nil, fmt.Errorf("why %d: %w", w.ID, err)
+		}
+		startDate, err := parseOptionalDate(w.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("why %d: %w", w.ID, err)
+		}
+		targetDate, err := parseOptionalDate(w.TargetDate)
+		if err != nil {
+			return nil, fmt.Errorf("why %d: %w", w.ID, err)
+		}
+		why := Why{
+			CreatedAt:   w.CreatedAt,
+			StartDate:   startDate,
+			TargetDate:  targetDate,
+			Target:      target,
+			Measure:     measure,
+			Name:        w.Name,
+			Description: w.Description,
+			Code:        code,
+			Number:      next,
+			Color:       lipgloss.Color(w.Color),
+			Archived:    w.Archived,
+		}
+		next++
+		if err := tx.Create(&why).Error; err != nil {
+			return nil, err
+		}
+		ids[w.ID] = why.ID
+		byName[why.Name] = why.ID
+		added = append(added, w)
+		stats.Added["whys"]++
+	}
+
+	// sub-goals are linked to their parents once every goal has an ID
+	for _, w := range added {
+		if w.ParentID == nil {
+			continue
+		}
+		parent, ok := ids[*w.ParentID]
+		if !ok {
+			return nil, fmt.Errorf("why %d: no why with id %d to be a sub-goal of", w.ID, *w.ParentID)
+		}
+		if err := tx.Model(&Why{}).Where("id = ?", ids[w.ID]).Update("parent_id", parent).Error; err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
+
+// importIntentions returns a map from the IDs of intentions in the document
+// to their IDs in the store
+func importIntentions(tx *gorm.DB, intentions []ExportIntention, whyIDs, recurringIDs map[uint]uint, stats ImportStats) (map[uint]uint, error) {
+	ids := make(map[uint]uint)
+	var existing []Intention
+	if err := tx.Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]uint)
+	for _, i := range existing {
+		seen[i.Date.Format(dateLayout)+"\x00"+i.Content] = i.ID
+	}
+	var whys []Why
+	if err := tx.Find(&whys).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*Why)
+	for i := range whys {
+		byID[whys[i].ID] = &whys[i]
+	}
+
+	for _, e := range intentions {
+		date, err := parseExportDate(e.Date)
+		if err != nil {
+			return nil, fmt.Errorf("intention %d: %w", e.ID, err)
+		}
+		var linked []*Why
+		for _, whyID := range e.WhyIDs {
+			id, ok := whyIDs[whyID]
+			if !ok {
+				return nil, fmt.Errorf("intention %d: no why with id %d", e.ID, whyID)
+			}
+			linked = append(linked, byID[id])
+		}
+		// prefixes are rewritten with the codes the goals have in the store
+		content := WithPrefix(e.Content, linked)
+		k := date.Format(dateLayout) + "\x00" + content
+		if id, ok := seen[k]; ok {
+			ids[e.ID] = id
+			stats.Skipped["intentions"]++
+			continue
+		}
+		intention := Intention{
+			Date:          date,
+			Content:       content,
+			Done:          e.Done,
+			Cancelled:     e.Cancelled,
+			Outcome:       e.Outcome,
+			Unintended:    e.Unintended,
+			Position:      e.Position,
+			Pomos:         e.Pomos,
+			Interruptions: e.Interruptions,
+			Planned:       e.Planned,
+		}
+		if e.CarriedFromID != nil {
+			// intentions are exported in date order, so the origin has
+			// already been imported
+			id, ok := ids[*e.CarriedFromID]
+			if !ok {
+				return nil, fmt.Errorf("intention %d: no earlier intention with id %d to carry over from", e.ID, *e.CarriedFromID)
+			}
+			intention.CarriedFromID = &id
+		}
+		if e.RecurringID != nil {
+			id, ok := recurringIDs[*e.RecurringID]
+			if !ok {
+				return nil, fmt.Errorf("intention %d: no recurring intention with id %d", e.ID, *e.RecurringID)
+			}
+			intention.RecurringID = &id
+		}
+		for _, why := range linked {
+			intention.Whys = append(intention.Whys, &Why{ID: why.ID})
+		}
+		// Only the links to the whys are created, not the whys themselves
+		err = tx.Omit("Whys.*").Create(&intention).Error
+		if err != nil {
+			return nil, err
+		}
+		ids[e.ID] = intention.ID
+		seen[k] = intention.ID
+		stats.Added["intentions"]++
+	}
+	return ids, nil
+}
+
+// importRecurring returns a map from the IDs of recurring intentions in the
+// document to their IDs in the store
+func importRecurring(tx *gorm.DB, recurring []ExportRecurring, whyIDs map[uint]uint, stats ImportStats) (map[uint]uint, error) {
+	ids := make(map[uint]uint)
+	var existing []RecurringIntention
+	if err := tx.Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]uint)
+	for _, r := range existing {
+		seen[r.Schedule.String()+"\x00"+r.Content] = r.ID
+	}
+
+	for _, e := range recurring {
+		start, err := parseExportDate(e.Start)
+		if err != nil {
+			return nil, fmt.Errorf("recurring intention %d: %w", e.ID, err)
+		}
+		schedule, err := ParseSchedule(e.Schedule, start)
+		if err != nil {
+			return nil, fmt.Errorf("recurring intention %d: %w", e.ID, err)
+		}
+		k := schedule.String() + "\x00" + e.Content
+		if id, ok := seen[k]; ok {
+			ids[e.ID] = id
+			stats.Skipped["recurring"]++
+			continue
+		}
+		r := RecurringIntention{CreatedAt: e.CreatedAt, Content: e.Content, Schedule: schedule}
+		for _, whyID := range e.WhyIDs {
+			id, ok := whyIDs[whyID]
+			if !ok {
+				return nil, fmt.Errorf("recurring intention %d: no why with id %d", e.ID, whyID)
+			}
+			r.Whys = append(r.Whys, &Why{ID: id})
+		}
+		if err := tx.Omit("Whys.*").Create(&r).Error; err != nil {
+			return nil, err
+		}
+		ids[e.ID] = r.ID
+		seen[k] = r.ID
+		stats.Added["recurring"]++
+	}
+	return ids, nil
+}
+
+func importDays(tx *gorm.DB, days []ExportDay, whyIDs map[uint]uint, stats ImportStats) error {
+	var existing []Day
+	if err := tx.Find(&existing).Error; err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, day := range existing {
+		seen[fmt.Sprint(day.Date.Format(dateLayout), day.WhyID)] = true
+	}
+
+	for _, e := range days {
+		date, err := parseExportDate(e.Date)
+		if err != nil {
+			return fmt.Errorf("day review: %w", err)
+		}
+		whyID, err := remapWhy(whyIDs, e.WhyID)
+		if err != nil {
+			return fmt.Errorf("day review of %s: %w", e.Date, err)
+		}
+		k := fmt.Sprint(date.Format(dateLayout), whyID)
+		if seen[k] {
+			stats.Skipped["days"]++
+			continue
+		}
+		day := Day{Date: date, WhyID: whyID, Enough: e.Enough, Reflection: e.Reflection}
+		if err := tx.Omit(clause.Associations).Create(&day).Error; err != nil {
+			return err
+		}
+		seen[k] = true
+		stats.Added["days"]++
+	}
+	return nil
+}
+
+func importReviews(tx *gorm.DB, reviews []ExportReview, whyIDs map[uint]uint, stats ImportStats) error {
+	var existing []Review
+	if err := tx.Find(&existing).Error; err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, r := range existing {
+		seen[fmt.Sprint(r.Period, r.Start.Format(dateLayout), r.WhyID)] = true
+	}
+
+	for _, e := range reviews {
+		period, err := parsePeriod(e.Period)
+		if err != nil {
+			return err
+		}
+		start, err := parseExportDate(e.Start)
+		if err != nil {
+			return fmt.Errorf("%s review: %w", e.Period, err)
+		}
+		end, err := parseExportDate(e.End)
+		if err != nil {
+			return fmt.Errorf("%s review: %w", e.Period, err)
+		}
+		whyID, err := remapWhy(whyIDs, e.WhyID)
+		if err != nil {
+			return fmt.Errorf("%s review of %s: %w", e.Period, e.Start, err)
+		}
+		k := fmt.Sprint(period, start.Format(dateLayout), whyID)
+		if seen[k] {
+			stats.Skipped["reviews"]++
+			continue
+		}
+		review := Review{
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+			Period:    period,
+			Start:     start,
+			End:       end,
+			WhyID:     whyID,
+			Progress:  e.Progress,
+			Obstacles: e.Obstacles,
+			Next:      e.Next,
+		}
+		if err := tx.Omit(clause.Associations).Create(&review).Error; err != nil {
+			return err
+		}
+		seen[k] = true
+		stats.Added["reviews"]++
+	}
+	return nil
+}
+
+func importSessions(tx *gorm.DB, sessions []ExportSession, intentionIDs map[uint]uint, stats ImportStats) error {
+	var existing []PomoSession
+	if err := tx.Find(&existing).Error; err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, p := range existing {
+		seen[fmt.Sprint(p.IntentionID, p.Start.Unix())] = true
+	}
+
+	for _, e := range sessions {
+		intentionID, ok := intentionIDs[e.IntentionID]
+		if !ok {
+			return fmt.Errorf("session at %s: no intention with id %d", e.Start, e.IntentionID)
+		}
+		k := fmt.Sprint(intentionID, e.Start.Unix())
+		if seen[k] {
+			stats.Skipped["sessions"]++
+			continue
+		}
+		session := PomoSession{
+			IntentionID: intentionID,
+			Start:       e.Start,
+			End:         e.End,
+			Duration:    e.Duration,
+			Completed:   e.Completed,
+		}
+		if err := tx.Omit(clause.Associations).Create(&session).Error; err != nil {
+			return err
+		}
+		seen[k] = true
+		stats.Added["sessions"]++
+	}
+	return nil
+}
+
+// remapWhy maps the ID of a why in a document to its ID in the store. The ID 0
+// stands for no why and is kept as it is.
+func remapWhy(whyIDs map[uint]uint, id uint) (uint, error) {
+	if id == 0 {
+		return 0, nil
+	}
+	mapped, ok := whyIDs[id]
+	if !ok {
+		return 0, fmt.Errorf("no why with id %d", id)
+	}
+	return mapped, nil
+}
+
+func parseExportDate(s string) (time.Time, error) {
+	date, err := time.ParseInLocation(dateLayout, s, time.UTC)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return date, nil
+}
+
+// parseOptionalDate reads a date which may be left out, as the empty string
+func parseOptionalDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	date, err := parseExportDate(s)
+	return &date, err
+}
+
+func formatOptionalDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(dateLayout)
+}
+
+func parsePeriod(s string) (Period, error) {
+	for p := Weekly; p <= Yearly; p++ {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	return Weekly, fmt.Errorf("unknown review period %q", s)
+}
@@ -0,0 +1,155 @@
+package data
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fillStore adds one of everything which is exported to store
+func fillStore(t *testing.T, store Store) {
+	t.Helper()
+	start, target := date(t, "2026-10-01"), date(t, "2026-12-31")
+	whys := []Why{
+		{Name: "Work", Code: "w", Number: 0, Color: "#FF0000",
+			StartDate: &start, TargetDate: &target, Target: 50, Measure: MeasurePomos},
+		{Name: "Health", Code: "h", Number: 1, Color: "#00FF00"},
+		{Name: "Old", Code: "o", Number: 2, Color: "#0000FF", Archived: true},
+	}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	parent := whys[0].ID
+	sub := []Why{{Name: "Report", Code: "r", Number: 3, Color: "#FF0000", ParentID: &parent}}
+	if err := store.UpsertWhys(sub); err != nil {
+		t.Fatal(err)
+	}
+
+	schedule, err := ParseSchedule("mon,thu", date(t, "2026-10-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recurring := []RecurringIntention{{Content: "stretch", Schedule: schedule, Whys: []*Why{&whys[1]}}}
+	if err := store.UpsertRecurringIntentions(recurring); err != nil {
+		t.Fatal(err)
+	}
+
+	day := date(t, "2026-10-15")
+	intentions := []Intention{
+		{Date: day, Content: "w,r) draft", Done: true, Pomos: 2, Whys: []*Why{&whys[0], &sub[0]}},
+		{Date: day, Content: "h) stretch", Position: 1, Whys: []*Why{&whys[1]}, RecurringID: &recurring[0].ID},
+	}
+	if err := store.UpsertIntentions(intentions); err != nil {
+		t.Fatal(err)
+	}
+	// added in date order, as imports are, so that they keep their IDs
+	later := []Intention{
+		{Date: day.AddDate(0, 0, 1), Content: "h) stretch", Whys: []*Why{&whys[1]}, CarriedFromID: &intentions[1].ID},
+		{Date: day.AddDate(0, 0, 2), Content: "&) later", Planned: true},
+	}
+	if err := store.UpsertIntentions(later); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.UpsertDayReview([]Day{{Date: day, WhyID: whys[0].ID, Enough: true, Reflection: "good"}}); err != nil {
+		t.Fatal(err)
+	}
+	weekStart, weekEnd := Weekly.Bounds(day)
+	review := Review{Period: Weekly, Start: weekStart, End: weekEnd, WhyID: whys[0].ID, Progress: "some"}
+	if err := store.UpsertReviews([]Review{review}); err != nil {
+		t.Fatal(err)
+	}
+	began := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	session := PomoSession{IntentionID: intentions[0].ID, Start: began,
+		End: began.Add(25 * time.Minute), Duration: 25 * time.Minute, Completed: true}
+	if err := store.AddPomoSession(session); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// comparable clears what an export may differ in without losing anything
+func comparable(doc Export) Export {
+	doc.Exported = time.Time{}
+	for i := range doc.Whys {
+		doc.Whys[i].CreatedAt = time.Time{}
+	}
+	for i := range doc.Reviews {
+		doc.Reviews[i].CreatedAt = time.Time{}
+		doc.Reviews[i].UpdatedAt = time.Time{}
+	}
+	for i := range doc.Recurring {
+		doc.Recurring[i].CreatedAt = time.Time{}
+	}
+	return doc
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source := newTestStore(t)
+	fillStore(t, source)
+	doc, err := source.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dest := newTestStore(t)
+	stats, err := dest.Import(doc, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for kind, n := range stats.Skipped {
+		if n != 0 {
+			t.Errorf("skipped %d %s importing into an empty store", n, kind)
+		}
+	}
+	again, err := dest.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an empty store gives out the same IDs in the same order
+	if got, want := comparable(again), comparable(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("export after import differs:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	// importing again adds nothing
+	stats, err = dest.Import(doc, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for kind, n := range stats.Added {
+		if n != 0 {
+			t.Errorf("added %d %s importing the same document twice", n, kind)
+		}
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	source := newTestStore(t)
+	fillStore(t, source)
+	doc, err := source.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := newTestStore(t)
+	if _, err := dest.Import(doc, true); err != nil {
+		t.Fatal(err)
+	}
+	whys, err := dest.GetWhys(All)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(whys) != 0 {
+		t.Errorf("a dry run saved %d goals", len(whys))
+	}
+}
+
+func TestImportRejectsNewerVersion(t *testing.T) {
+	store := newTestStore(t)
+	_, err := store.Import(Export{Version: ExportVersion + 1}, false)
+	if err == nil || !strings.Contains(err.Error(), "unsupported export version") {
+		t.Errorf("got %v, want an unsupported version error", err)
+	}
+	if _, err := store.Import(Export{Version: 1}, false); err != nil {
+		t.Errorf("version 1 documents should still be read: %v", err)
+	}
+}
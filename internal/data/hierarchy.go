@@ -0,0 +1,85 @@
+package data
+
+// Goals can have sub-goals, such as quarterly goals under a yearly one.
+// Outcomes, statistics and reviews are kept for top-level goals, which the
+// intentions of their sub-goals count towards.
+
+// Roots returns a map from the ID of each of whys to the ID of the top-level
+// goal it is under, which is its own if it has no parent. Parents which
+// aren't in whys are treated as if the goal had none.
+func Roots(whys []Why) map[uint]uint {
+	parents := make(map[uint]*uint)
+	for _, why := range whys {
+		parents[why.ID] = why.ParentID
+	}
+	roots := make(map[uint]uint)
+	for _, why := range whys {
+		root := why.ID
+		seen := map[uint]bool{root: true}
+		for {
+			parent, ok := parents[root]
+			if !ok || parent == nil || seen[*parent] {
+				break
+			}
+			if _, ok := parents[*parent]; !ok {
+				break
+			}
+			root = *parent
+			seen[root] = true
+		}
+		roots[why.ID] = root
+	}
+	return roots
+}
+
+// RootOf returns the ID of the top-level goal which the goal with id is under,
+// from a map made by Roots
+func RootOf(roots map[uint]uint, id uint) uint {
+	if root, ok := roots[id]; ok {
+		return root
+	}
+	return id
+}
+
+// RollUp returns copies of intentions and days in which each goal is
+// replaced by the top-level goal it is under, out of whys. Intentions linked
+// to several goals under the same top-level one are linked to it once.
+func RollUp(intentions []Intention, days []Day, whys []Why) ([]Intention, []Day) {
+	roots := Roots(whys)
+	byID := make(map[uint]*Why)
+	for i := range whys {
+		byID[whys[i].ID] = &whys[i]
+	}
+
+	var rolledIntentions []Intention
+	for _, intention := range intentions {
+		var linked []*Why
+		seen := make(map[uint]bool)
+		for _, why := range intention.Whys {
+			id := RootOf(roots, why.ID)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if root, ok := byID[id]; ok {
+				linked = append(linked, root)
+			} else {
+				linked = append(linked, why)
+			}
+		}
+		intention.Whys = linked
+		rolledIntentions = append(rolledIntentions, intention)
+	}
+
+	var rolledDays []Day
+	for _, day := range days {
+		if day.WhyID != 0 {
+			day.WhyID = RootOf(roots, day.WhyID)
+			if root, ok := byID[day.WhyID]; ok {
+				day.Why = *root
+			}
+		}
+		rolledDays = append(rolledDays, day)
+	}
+	return rolledIntentions, rolledDays
+}
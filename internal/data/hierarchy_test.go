@@ -0,0 +1,91 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+// goalTree returns goals with the IDs given, each under the parent it is
+// mapped to, or none if that is 0
+func goalTree(parents map[uint]uint) []Why {
+	var whys []Why
+	for id := uint(1); id <= uint(len(parents)); id++ {
+		why := Why{Code: string(rune('a' + id - 1))}
+		why.ID = id
+		if parent := parents[id]; parent != 0 {
+			why.ParentID = &parent
+		}
+		whys = append(whys, why)
+	}
+	return whys
+}
+
+func TestRoots(t *testing.T) {
+	tests := []struct {
+		name    string
+		parents map[uint]uint
+		want    map[uint]uint
+	}{
+		{"flat", map[uint]uint{1: 0, 2: 0}, map[uint]uint{1: 1, 2: 2}},
+		{"nested", map[uint]uint{1: 0, 2: 1, 3: 2}, map[uint]uint{1: 1, 2: 1, 3: 1}},
+		{"missing parent", map[uint]uint{1: 0, 2: 9}, map[uint]uint{1: 1, 2: 2}},
+		// a cycle, which the goals page can't make, stops rather than loops
+		{"cycle", map[uint]uint{1: 2, 2: 1}, map[uint]uint{1: 2, 2: 1}},
+	}
+	for _, tt := range tests {
+		if got := Roots(goalTree(tt.parents)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Roots = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRootOf(t *testing.T) {
+	roots := map[uint]uint{1: 1, 2: 1}
+	if got := RootOf(roots, 2); got != 1 {
+		t.Errorf("RootOf(2) = %d, want 1", got)
+	}
+	if got := RootOf(roots, 5); got != 5 {
+		t.Errorf("RootOf(5) = %d, want a goal which isn't known to be its own root", got)
+	}
+}
+
+func TestRollUp(t *testing.T) {
+	// 1 has sub-goal 2, which has sub-goal 3; 4 stands alone
+	whys := goalTree(map[uint]uint{1: 0, 2: 1, 3: 2, 4: 0})
+	unknown := &Why{Code: "z"}
+	unknown.ID = 9
+	intentions := []Intention{
+		{Content: "a) top", Whys: []*Why{&whys[0]}},
+		{Content: "c) deep", Whys: []*Why{&whys[2]}},
+		{Content: "b,c,d) several", Whys: []*Why{&whys[1], &whys[2], &whys[3]}},
+		{Content: "z) unknown", Whys: []*Why{unknown}},
+		{Content: "&) none"},
+	}
+	days := []Day{{WhyID: 3}, {WhyID: 4}, {}}
+
+	rolled, rolledDays := RollUp(intentions, days, whys)
+	want := [][]uint{{1}, {1}, {1, 4}, {9}, nil}
+	for i, intention := range rolled {
+		var ids []uint
+		for _, why := range intention.Whys {
+			ids = append(ids, why.ID)
+		}
+		if !reflect.DeepEqual(ids, want[i]) {
+			t.Errorf("%q rolled up to %v, want %v", intention.Content, ids, want[i])
+		}
+	}
+	if len(intentions[1].Whys) != 1 || intentions[1].Whys[0].ID != 3 {
+		t.Error("RollUp changed the intentions it was given")
+	}
+
+	var dayIDs []uint
+	for _, day := range rolledDays {
+		dayIDs = append(dayIDs, day.WhyID)
+	}
+	if want := []uint{1, 4, 0}; !reflect.DeepEqual(dayIDs, want) {
+		t.Errorf("days rolled up to %v, want %v", dayIDs, want)
+	}
+	if rolledDays[0].Why.Code != "a" {
+		t.Errorf("rolled up day has goal %q, want a", rolledDays[0].Why.Code)
+	}
+}
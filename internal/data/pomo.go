@@ -0,0 +1,39 @@
+package data
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// PomoSession records one pomodoro worked on an intention with the timer
+type PomoSession struct {
+	ID          uint
+	IntentionID uint
+	Intention   Intention
+
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	// Completed is false for pomodoros that were interrupted before the
+	// timer ran out
+	Completed bool
+}
+
+func (s *Store) AddPomoSession(session PomoSession) error {
+	return s.db.Omit(clause.Associations).Create(&session).Error
+}
+
+// GetPomoSessionsBetween returns the sessions worked on intentions dated from
+// start up to and including end, ordered by when they started. Sessions are
+// grouped by the date of their intention rather than the time they were
+// worked, so one started after midnight still belongs to the previous day.
+func (s *Store) GetPomoSessionsBetween(start, end time.Time) ([]PomoSession, error) {
+	var results []PomoSession
+	err := s.db.Model(&PomoSession{}).Preload("Intention.Whys").
+		Joins("JOIN intentions ON intentions.id = pomo_sessions.intention_id").
+		Where("intentions.date >= ? AND intentions.date <= ?", DateOf(start), DateOf(end)).
+		Order("pomo_sessions.start").
+		Find(&results).Error
+	return results, err
+}
@@ -0,0 +1,59 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetPomoSessionsBetween(t *testing.T) {
+	store := newTestStore(t)
+	whys := []Why{{Name: "Work", Code: "w"}}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	day := date(t, "2026-10-15")
+	intentions := []Intention{
+		{Date: day, Content: "w) draft", Whys: []*Why{&whys[0]}},
+		{Date: day.AddDate(0, 0, 1), Content: "&) next day"},
+	}
+	if err := store.UpsertIntentions(intentions); err != nil {
+		t.Fatal(err)
+	}
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	sessions := []PomoSession{
+		// worked after midnight, but on the 15th's intention
+		{IntentionID: intentions[0].ID, Start: at(24, 10), End: at(24, 35), Duration: 25 * time.Minute, Completed: true},
+		{IntentionID: intentions[0].ID, Start: at(9, 0), End: at(9, 10), Duration: 10 * time.Minute},
+		{IntentionID: intentions[1].ID, Start: at(33, 0), End: at(33, 25), Duration: 25 * time.Minute, Completed: true},
+	}
+	for _, session := range sessions {
+		if err := store.AddPomoSession(session); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := store.GetPomoSessionsBetween(day, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d sessions on %s, want 2", len(got), day.Format("2006-01-02"))
+	}
+	if !got[0].Start.Equal(at(9, 0)) || got[0].Completed || got[0].Duration != 10*time.Minute ||
+		!got[1].Start.Equal(at(24, 10)) || !got[1].Completed {
+		t.Errorf("got %+v, want the interrupted session first and the one after midnight second", got)
+	}
+	if got[0].Intention.Content != "w) draft" || len(got[0].Intention.Whys) != 1 {
+		t.Errorf("got intention %+v, want w) draft read with its goal", got[0].Intention)
+	}
+
+	got, err = store.GetPomoSessionsBetween(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Errorf("got %d sessions over two days, want 3", len(got))
+	}
+}
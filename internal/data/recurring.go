@@ -0,0 +1,183 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ScheduleKind is the way in which a recurring intention repeats
+type ScheduleKind int
+
+const (
+	// EveryDay repeats on every day
+	EveryDay ScheduleKind = iota
+	// EveryWeekday repeats from Monday to Friday
+	EveryWeekday
+	// OnDays repeats on the days of the week in Schedule.Days
+	OnDays
+	// EveryNDays repeats every Schedule.Interval days from Schedule.Start
+	EveryNDays
+	// OnDayOfMonth repeats on the Schedule.DayOfMonth of each month, or on its
+	// last day if the month is shorter
+	OnDayOfMonth
+)
+
+// Schedule says on which days a recurring intention is due
+type Schedule struct {
+	Kind ScheduleKind
+	// Days is a bit mask with the bit 1<<time.Weekday set for each day an
+	// OnDays schedule is due
+	Days       int
+	Interval   int
+	DayOfMonth int
+	Start      time.Time
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ParseSchedule parses a schedule written as "daily", "weekdays", a list of
+// days like "mon,wed,fri", "every:N" for every N days counting from start, or
+// "monthly:D" for the Dth of every month. String is its inverse.
+func ParseSchedule(spec string, start time.Time) (Schedule, error) {
+	s := Schedule{Start: start}
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "daily":
+		s.Kind = EveryDay
+		return s, nil
+	case "weekdays":
+		s.Kind = EveryWeekday
+		return s, nil
+	case "every":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return s, fmt.Errorf("invalid schedule %q: expected every:N with N at least 1", spec)
+		}
+		s.Kind = EveryNDays
+		s.Interval = n
+		return s, nil
+	case "monthly":
+		d, err := strconv.Atoi(arg)
+		if err != nil || d < 1 || d > 31 {
+			return s, fmt.Errorf("invalid schedule %q: expected monthly:D with D from 1 to 31", spec)
+		}
+		s.Kind = OnDayOfMonth
+		s.DayOfMonth = d
+		return s, nil
+	}
+
+	s.Kind = OnDays
+	for _, name := range strings.Split(spec, ",") {
+		found := false
+		for d, weekday := range weekdayNames {
+			if strings.TrimSpace(name) == weekday {
+				s.Days |= 1 << d
+				found = true
+			}
+		}
+		if !found {
+			return s, fmt.Errorf("invalid schedule %q: expected daily, weekdays, days like mon,thu, every:N or monthly:D", spec)
+		}
+	}
+	return s, nil
+}
+
+func (s Schedule) String() string {
+	switch s.Kind {
+	case EveryDay:
+		return "daily"
+	case EveryWeekday:
+		return "weekdays"
+	case EveryNDays:
+		return fmt.Sprintf("every:%d", s.Interval)
+	case OnDayOfMonth:
+		return fmt.Sprintf("monthly:%d", s.DayOfMonth)
+	}
+	var days []string
+	for d, name := range weekdayNames {
+		if s.Days&(1<<d) != 0 {
+			days = append(days, name)
+		}
+	}
+	return strings.Join(days, ",")
+}
+
+// Due reports whether the schedule has an instance on day
+func (s Schedule) Due(day time.Time) bool {
+	if day.Before(s.Start) {
+		return false
+	}
+	switch s.Kind {
+	case EveryDay:
+		return true
+	case EveryWeekday:
+		return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+	case OnDays:
+		return s.Days&(1<<day.Weekday()) != 0
+	case EveryNDays:
+		// round to whole days, which may be an hour more or less than 24
+		// hours apart across daylight saving changes
+		days := int(day.Sub(s.Start).Hours()/24 + 0.5)
+		return days%s.Interval == 0
+	case OnDayOfMonth:
+		year, month, date := day.Date()
+		last := time.Date(year, month+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		if s.DayOfMonth > last {
+			return date == last
+		}
+		return date == s.DayOfMonth
+	}
+	return false
+}
+
+// RecurringIntention is a rule from which an intention is created on every
+// day its schedule is due. The intentions are independent copies, so
+// changing one doesn't change the rule.
+type RecurringIntention struct {
+	ID        uint
+	CreatedAt time.Time
+
+	// Content is the intention without a goal prefix, which is added from
+	// Whys when an instance is created
+	Content  string
+	Schedule Schedule `gorm:"embedded;embeddedPrefix:schedule_"`
+
+	Whys []*Why `gorm:"many2many:whys_recurring_intentions;"`
+}
+
+func (s *Store) GetRecurringIntentions() ([]RecurringIntention, error) {
+	var results []RecurringIntention
+	err := s.db.Model(&RecurringIntention{}).Preload("Whys").Order("id").Find(&results).Error
+	return results, err
+}
+
+func (s *Store) UpsertRecurringIntentions(items []RecurringIntention) error {
+	for i := range items {
+		items[i].Schedule.Start = DateOf(items[i].Schedule.Start)
+	}
+	err := s.db.Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(&items).Error
+	return err
+}
+
+// DeleteRecurringIntention deletes a rule. Intentions already created from it
+// are kept.
+func (s *Store) DeleteRecurringIntention(item RecurringIntention) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&item).Association("Whys").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Model(&Intention{}).Where("recurring_id = ?", item.ID).
+			Update("recurring_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&item).Error
+	})
+}
@@ -0,0 +1,77 @@
+package data
+
+import "testing"
+
+func TestParseSchedule(t *testing.T) {
+	start := date(t, "2026-10-01")
+	tests := []struct {
+		spec string
+		want Schedule
+		// str is what String writes, if it differs from spec
+		str string
+	}{
+		{"daily", Schedule{Kind: EveryDay, Start: start}, ""},
+		{" Weekdays ", Schedule{Kind: EveryWeekday, Start: start}, "weekdays"},
+		{"mon,thu", Schedule{Kind: OnDays, Days: 1<<1 | 1<<4, Start: start}, ""},
+		{"sat, sun", Schedule{Kind: OnDays, Days: 1<<6 | 1<<0, Start: start}, "sun,sat"},
+		{"every:3", Schedule{Kind: EveryNDays, Interval: 3, Start: start}, ""},
+		{"monthly:31", Schedule{Kind: OnDayOfMonth, DayOfMonth: 31, Start: start}, ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseSchedule(tt.spec, start)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): %v", tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSchedule(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+		str := tt.str
+		if str == "" {
+			str = tt.spec
+		}
+		if got.String() != str {
+			t.Errorf("ParseSchedule(%q).String() = %q, want %q", tt.spec, got.String(), str)
+		}
+	}
+
+	for _, spec := range []string{"", "hourly", "every:0", "every:x", "monthly:0", "monthly:32", "mon,funday"} {
+		if _, err := ParseSchedule(spec, start); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestScheduleDue(t *testing.T) {
+	tests := []struct {
+		spec  string
+		start string
+		due   []string
+		not   []string
+	}{
+		// 2026-10-01 is a Thursday
+		{"daily", "2026-10-01", []string{"2026-10-01", "2026-10-04"}, []string{"2026-09-30"}},
+		{"weekdays", "2026-10-01", []string{"2026-10-02", "2026-10-05"}, []string{"2026-10-03", "2026-10-04"}},
+		{"mon,thu", "2026-10-01", []string{"2026-10-01", "2026-10-05"}, []string{"2026-10-02", "2026-09-28"}},
+		{"every:3", "2026-10-01", []string{"2026-10-01", "2026-10-04", "2026-10-31"}, []string{"2026-10-02", "2026-10-03"}},
+		// months shorter than the day fall on their last day
+		{"monthly:31", "2026-01-01", []string{"2026-01-31", "2026-02-28", "2026-04-30"}, []string{"2026-04-29", "2026-02-27"}},
+		{"monthly:15", "2026-10-20", []string{"2026-11-15"}, []string{"2026-10-15"}},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.spec, date(t, tt.start))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range tt.due {
+			if !s.Due(date(t, d)) {
+				t.Errorf("%s from %s isn't due on %s", tt.spec, tt.start, d)
+			}
+		}
+		for _, d := range tt.not {
+			if s.Due(date(t, d)) {
+				t.Errorf("%s from %s is due on %s", tt.spec, tt.start, d)
+			}
+		}
+	}
+}
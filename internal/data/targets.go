@@ -0,0 +1,140 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Goals can have a target date, and a target amount of pomodoros or of done
+// intentions to reach by then. Progress towards it is counted from the
+// goal's start date, over its intentions and those of its sub-goals.
+
+type MeasureEnum int
+
+const (
+	MeasurePomos MeasureEnum = iota
+	MeasureDone
+)
+
+func (m MeasureEnum) String() string {
+	if m == MeasureDone {
+		return "done"
+	}
+	return "pomos"
+}
+
+// FormatTarget returns the goal's target amount as ParseTarget reads it, or
+// "" if it has none
+func FormatTarget(why Why) string {
+	if why.Target <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d %s", why.Target, why.Measure)
+}
+
+// ParseTarget reads a target amount such as "50 pomos" or "20 done". An empty
+// string is no target.
+func ParseTarget(s string) (int, MeasureEnum, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, MeasurePomos, nil
+	}
+	n, err := strconv.Atoi(fields[0])
+	if err != nil || n <= 0 || len(fields) != 2 {
+		return 0, MeasurePomos, fmt.Errorf("invalid target %q, expected an amount like \"50 pomos\" or \"20 done\"", s)
+	}
+	switch unit := strings.ToLower(fields[1]); {
+	case strings.HasPrefix(unit, "pomo"):
+		return n, MeasurePomos, nil
+	case unit == "done" || strings.HasPrefix(unit, "intention"):
+		return n, MeasureDone, nil
+	}
+	return 0, MeasurePomos, fmt.Errorf("invalid target %q, expected pomos or done", s)
+}
+
+// Progress is what has been done towards a goal since its start date
+type Progress struct {
+	Pomos int
+	Done  int
+}
+
+// Amount returns the progress counted in measure
+func (p Progress) Amount(measure MeasureEnum) int {
+	if measure == MeasureDone {
+		return p.Done
+	}
+	return p.Pomos
+}
+
+// DaysLeft returns the number of days from day until the goal's target date,
+// which is negative once it has passed. ok is false if it has none.
+func DaysLeft(why Why, day time.Time) (days int, ok bool) {
+	if why.TargetDate == nil {
+		return 0, false
+	}
+	return int(math.Round(DateOf(*why.TargetDate).Sub(DateOf(day)).Hours() / 24)), true
+}
+
+// GetProgress returns the progress of every goal, counting the intentions of
+// its sub-goals, and only intentions on or after its start date. Intentions
+// which were cancelled or are only planned don't count.
+func (s *Store) GetProgress() (map[uint]Progress, error) {
+	var whys []Why
+	if err := s.db.Find(&whys).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]Why)
+	for _, why := range whys {
+		byID[why.ID] = why
+	}
+
+	var links []struct {
+		IntentionID uint
+		WhyID       uint
+		Date        time.Time
+		Pomos       int
+		Done        bool
+	}
+	err := s.db.Table("intentions").
+		Select("intentions.id AS intention_id, whys_intentions.why_id, intentions.date, intentions.pomos, intentions.done").
+		Joins("JOIN whys_intentions ON whys_intentions.intention_id = intentions.id").
+		Where("intentions.cancelled = 0 AND intentions.planned = 0").
+		Scan(&links).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]Progress)
+	// an intention linked to a goal and its sub-goal is counted once
+	counted := make(map[[2]uint]bool)
+	for _, link := range links {
+		id := link.WhyID
+		seen := make(map[uint]bool)
+		for id != 0 && !seen[id] {
+			seen[id] = true
+			why, ok := byID[id]
+			if !ok {
+				break
+			}
+			k := [2]uint{id, link.IntentionID}
+			started := why.StartDate == nil || !DateOf(link.Date).Before(DateOf(*why.StartDate))
+			if started && !counted[k] {
+				counted[k] = true
+				p := result[id]
+				p.Pomos += link.Pomos
+				if link.Done {
+					p.Done++
+				}
+				result[id] = p
+			}
+			id = 0
+			if why.ParentID != nil {
+				id = *why.ParentID
+			}
+		}
+	}
+	return result, nil
+}
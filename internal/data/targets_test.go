@@ -0,0 +1,134 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		s       string
+		n       int
+		measure MeasureEnum
+		err     bool
+	}{
+		{"", 0, MeasurePomos, false},
+		{"  ", 0, MeasurePomos, false},
+		{"50 pomos", 50, MeasurePomos, false},
+		{"1 pomodoro", 1, MeasurePomos, false},
+		{"20 done", 20, MeasureDone, false},
+		{"20 Intentions", 20, MeasureDone, false},
+		{"50", 0, MeasurePomos, true},
+		{"0 pomos", 0, MeasurePomos, true},
+		{"-3 done", 0, MeasurePomos, true},
+		{"lots pomos", 0, MeasurePomos, true},
+		{"5 hours", 0, MeasurePomos, true},
+		{"5 done today", 0, MeasurePomos, true},
+	}
+	for _, tt := range tests {
+		n, measure, err := ParseTarget(tt.s)
+		if (err != nil) != tt.err || n != tt.n || measure != tt.measure {
+			t.Errorf("ParseTarget(%q) = %d, %s, %v, want %d, %s, error %v",
+				tt.s, n, measure, err, tt.n, tt.measure, tt.err)
+		}
+	}
+}
+
+func TestFormatTarget(t *testing.T) {
+	tests := []struct {
+		why  Why
+		want string
+	}{
+		{Why{}, ""},
+		{Why{Target: 50, Measure: MeasurePomos}, "50 pomos"},
+		{Why{Target: 20, Measure: MeasureDone}, "20 done"},
+	}
+	for _, tt := range tests {
+		got := FormatTarget(tt.why)
+		if got != tt.want {
+			t.Errorf("FormatTarget(%+v) = %q, want %q", tt.why, got, tt.want)
+		}
+		// what is formatted reads back as the same target
+		n, measure, err := ParseTarget(got)
+		if err != nil || n != tt.why.Target || (n > 0 && measure != tt.why.Measure) {
+			t.Errorf("ParseTarget(%q) = %d, %s, %v", got, n, measure, err)
+		}
+	}
+}
+
+func TestDaysLeft(t *testing.T) {
+	target := date(t, "2026-10-20")
+	why := Why{TargetDate: &target}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		day  time.Time
+		want int
+	}{
+		{date(t, "2026-10-18"), 2},
+		{date(t, "2026-10-20"), 0},
+		{date(t, "2026-10-23"), -3},
+		// late on the 19th in Berlin is already the 19th, not the 20th
+		{time.Date(2026, 10, 19, 23, 30, 0, 0, berlin), 1},
+		// across the end of summer time
+		{date(t, "2026-10-01"), 19},
+	}
+	for _, tt := range tests {
+		got, ok := DaysLeft(why, tt.day)
+		if !ok || got != tt.want {
+			t.Errorf("DaysLeft(%s) = %d, %v, want %d", tt.day, got, ok, tt.want)
+		}
+	}
+	if _, ok := DaysLeft(Why{}, date(t, "2026-10-18")); ok {
+		t.Error("DaysLeft is ok for a goal without a target date")
+	}
+}
+
+func TestGetProgress(t *testing.T) {
+	store := newTestStore(t)
+	start := date(t, "2026-10-10")
+	whys := []Why{
+		{Name: "Work", Code: "w", StartDate: &start, Target: 10, Measure: MeasurePomos},
+		{Name: "Health", Code: "h", Number: 1},
+	}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	parent := whys[0].ID
+	sub := []Why{{Name: "Report", Code: "r", Number: 2, ParentID: &parent}}
+	if err := store.UpsertWhys(sub); err != nil {
+		t.Fatal(err)
+	}
+
+	day := date(t, "2026-10-15")
+	intentions := []Intention{
+		// before Work started, so only Health counts it
+		{Date: date(t, "2026-10-01"), Content: "w,h) early", Done: true, Pomos: 1, Whys: []*Why{&whys[0], &whys[1]}},
+		{Date: day, Content: "w) draft", Done: true, Pomos: 2, Whys: []*Why{&whys[0]}},
+		// linked to Work and its sub-goal, but counted towards Work once
+		{Date: day, Content: "w,r) write", Done: true, Pomos: 3, Whys: []*Why{&whys[0], &sub[0]}},
+		{Date: day, Content: "r) outline", Pomos: 1, Whys: []*Why{&sub[0]}},
+		{Date: day, Content: "h) cancelled", Cancelled: true, Pomos: 4, Whys: []*Why{&whys[1]}},
+		{Date: day.AddDate(0, 0, 1), Content: "h) planned", Planned: true, Whys: []*Why{&whys[1]}},
+	}
+	if err := store.UpsertIntentions(intentions); err != nil {
+		t.Fatal(err)
+	}
+
+	progress, err := store.GetProgress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uint]Progress{
+		whys[0].ID: {Pomos: 6, Done: 2},
+		whys[1].ID: {Pomos: 1, Done: 1},
+		sub[0].ID:  {Pomos: 4, Done: 1},
+	}
+	for id, p := range want {
+		if progress[id] != p {
+			t.Errorf("progress of goal %d = %+v, want %+v", id, progress[id], p)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Dates such as an intention's are calendar days, which are stored as
+// midnight UTC so that the same day is always written, and matched by
+// queries, the same way whatever zone the machine is in. The time zone in
+// which the current day is worked out is a setting of each database.
+
+// DateOf returns the calendar date of t, as it is in t's location, as
+// midnight UTC
+func DateOf(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Setting is a value stored with the rest of a database's data, so that it
+// belongs to a profile rather than to the machine
+type Setting struct {
+	Key   string `gorm:"primaryKey"`
+	Value string
+}
+
+const timezoneKey = "timezone"
+
+// Location returns the time zone in which the database's days are counted,
+// or the machine's zone if none has been recorded
+func (s *Store) Location() (*time.Location, error) {
+	var setting Setting
+	err := s.db.Where("key = ?", timezoneKey).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Local, nil
+	} else if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(setting.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored time zone: %w", err)
+	}
+	return loc, nil
+}
+
+// SetLocation records name, an IANA time zone such as "Europe/Berlin", as
+// the zone in which the database's days are counted
+func (s *Store) SetLocation(name string) error {
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("unknown time zone %q", name)
+	}
+	return s.db.Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(&Setting{Key: timezoneKey, Value: name}).Error
+}
+
+// localZoneName returns the IANA name of the machine's time zone, or "" if
+// it can't be found
+func localZoneName() string {
+	if tz := os.Getenv("TZ"); tz != "" {
+		if _, err := time.LoadLocation(tz); err == nil {
+			return tz
+		}
+	}
+	if target, err := filepath.EvalSymlinks("/etc/localtime"); err == nil {
+		if _, name, found := strings.Cut(target, "zoneinfo/"); found {
+			return name
+		}
+	}
+	if contents, err := os.ReadFile("/etc/timezone"); err == nil {
+		return strings.TrimSpace(string(contents))
+	}
+	return ""
+}
+
+// migrateDates records the machine's time zone for a database that has none
+// and rewrites dates stored as local midnight by earlier versions as
+// midnight UTC, keeping their calendar date
+func migrateDates(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&Setting{}).Where("key = ?", timezoneKey).Count(&count).Error; err != nil {
+		return err
+	}
+	if name := localZoneName(); count == 0 && name != "" {
+		if err := db.Create(&Setting{Key: timezoneKey, Value: name}).Error; err != nil {
+			return err
+		}
+	}
+
+	columns := []struct{ table, column string }{
+		{"intentions", "date"},
+		{"days", "date"},
+		{"reviews", "start"},
+		{"reviews", "end"},
+		{"recurring_intentions", "schedule_start"},
+		{"whys", "start_date"},
+		{"whys", "target_date"},
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, c := range columns {
+			midnight := fmt.Sprintf("substr(%q, 1, 10) || ' 00:00:00+00:00'", c.column)
+			err := tx.Exec(fmt.Sprintf("UPDATE %q SET %q = %s WHERE %q IS NOT NULL AND %q != %s",
+				c.table, c.column, midnight, c.column, c.column, midnight)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
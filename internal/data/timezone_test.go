@@ -0,0 +1,84 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateOf(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Date(2026, 10, 15, 0, 30, 0, 0, berlin), "2026-10-15"},
+		{time.Date(2026, 10, 15, 23, 59, 0, 0, berlin), "2026-10-15"},
+		{time.Date(2026, 10, 15, 23, 59, 0, 0, time.UTC), "2026-10-15"},
+	}
+	for _, tt := range tests {
+		if got := DateOf(tt.t); !got.Equal(date(t, tt.want)) || got.Location() != time.UTC {
+			t.Errorf("DateOf(%s) = %s, want %s", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateDates(t *testing.T) {
+	store := newTestStore(t)
+	target := date(t, "2026-12-31")
+	whys := []Why{{Name: "Work", Code: "w", TargetDate: &target}}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	day := date(t, "2026-10-15")
+	if err := store.UpsertIntentions([]Intention{{Date: day, Content: "w) draft", Whys: []*Why{&whys[0]}}}); err != nil {
+		t.Fatal(err)
+	}
+	// as earlier versions wrote them, at local midnight east of UTC
+	for _, stmt := range []string{
+		"UPDATE intentions SET date = '2026-10-15 00:00:00+02:00'",
+		"UPDATE whys SET target_date = '2026-12-31 00:00:00+01:00'",
+		"DELETE FROM settings",
+	} {
+		if err := store.db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("TZ", "Europe/Berlin")
+
+	if err := migrateDates(store.db); err != nil {
+		t.Fatal(err)
+	}
+	intentions, err := store.GetDaysIntentions(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intentions) != 1 {
+		t.Errorf("got %d intentions on %s after migrating, want 1", len(intentions), day.Format("2006-01-02"))
+	}
+	got, err := store.GetWhys(All)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].TargetDate == nil || !got[0].TargetDate.Equal(target) {
+		t.Errorf("got %+v, want the target date kept as %s", got, target.Format("2006-01-02"))
+	}
+	loc, err := store.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.String() != "Europe/Berlin" {
+		t.Errorf("recorded time zone %s, want Europe/Berlin", loc)
+	}
+
+	// a second run leaves the recorded zone alone
+	t.Setenv("TZ", "Asia/Tokyo")
+	if err := migrateDates(store.db); err != nil {
+		t.Fatal(err)
+	}
+	if loc, _ := store.Location(); loc.String() != "Europe/Berlin" {
+		t.Errorf("recorded time zone changed to %s", loc)
+	}
+}
@@ -0,0 +1,171 @@
+package data
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Undoing a change means putting back what it replaced, so these read rows
+// as they are before a change and write them back exactly, IDs and links to
+// goals included.
+
+// GetIntentionsByID returns the intentions with ids which exist, with their
+// goals
+func (s *Store) GetIntentionsByID(ids []uint) ([]Intention, error) {
+	var result []Intention
+	if len(ids) == 0 {
+		return result, nil
+	}
+	err := s.db.Preload("Whys").Where("id IN ?", ids).Find(&result).Error
+	return result, err
+}
+
+// RestoreIntentions saves intentions as they are, linking them to exactly
+// the goals in their Whys
+func (s *Store) RestoreIntentions(items []Intention) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return restoreIntentions(tx, items)
+	})
+}
+
+func restoreIntentions(tx *gorm.DB, items []Intention) error {
+	for i := range items {
+		items[i].Date = DateOf(items[i].Date)
+	}
+	err := tx.Omit("Whys", "CarriedFrom").Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(&items).Error
+	if err != nil {
+		return err
+	}
+	for _, i := range items {
+		if err := tx.Exec("DELETE FROM whys_intentions WHERE intention_id = ?", i.ID).Error; err != nil {
+			return err
+		}
+		for _, why := range i.Whys {
+			err := tx.Exec("INSERT OR IGNORE INTO whys_intentions (intention_id, why_id) VALUES (?, ?)", i.ID, why.ID).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetWhysByID returns the goals with ids which exist
+func (s *Store) GetWhysByID(ids []uint) ([]Why, error) {
+	var result []Why
+	if len(ids) == 0 {
+		return result, nil
+	}
+	err := s.db.Where("id IN ?", ids).Find(&result).Error
+	return result, err
+}
+
+// WhysSnapshot holds goals along with everything which DeleteWhys deletes or
+// changes with them
+type WhysSnapshot struct {
+	Whys []Why
+	// Intentions are those linked to the goals, whose prefixes are rewritten
+	// when the goals are deleted
+	Intentions []Intention
+	Days       []Day
+	Reviews    []Review
+	// RecurringLinks pairs the IDs of recurring intentions with those of the
+	// goals they are linked to
+	RecurringLinks [][2]uint
+	// Children are the goals' sub-goals, which are moved up a level
+	Children []Why
+}
+
+// SnapshotWhys reads whys as they are, along with what would be lost by
+// deleting them
+func (s *Store) SnapshotWhys(whys []Why) (WhysSnapshot, error) {
+	var snapshot WhysSnapshot
+	var ids []uint
+	for _, why := range whys {
+		if why.ID != 0 {
+			ids = append(ids, why.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return snapshot, nil
+	}
+	if err := s.db.Where("id IN ?", ids).Find(&snapshot.Whys).Error; err != nil {
+		return snapshot, err
+	}
+	err := s.db.Preload("Whys").
+		Where("id IN (SELECT intention_id FROM whys_intentions WHERE why_id IN ?)", ids).
+		Find(&snapshot.Intentions).Error
+	if err != nil {
+		return snapshot, err
+	}
+	if err := s.db.Where("why_id IN ?", ids).Find(&snapshot.Days).Error; err != nil {
+		return snapshot, err
+	}
+	if err := s.db.Where("why_id IN ?", ids).Find(&snapshot.Reviews).Error; err != nil {
+		return snapshot, err
+	}
+	var links []struct {
+		RecurringIntentionID uint
+		WhyID                uint
+	}
+	err = s.db.Table("whys_recurring_intentions").Where("why_id IN ?", ids).Scan(&links).Error
+	if err != nil {
+		return snapshot, err
+	}
+	for _, link := range links {
+		snapshot.RecurringLinks = append(snapshot.RecurringLinks, [2]uint{link.RecurringIntentionID, link.WhyID})
+	}
+	err = s.db.Where("parent_id IN ?", ids).Find(&snapshot.Children).Error
+	return snapshot, err
+}
+
+// RestoreWhys puts back goals deleted since snapshot was taken, along with
+// everything deleted with them
+func (s *Store) RestoreWhys(snapshot WhysSnapshot) error {
+	if len(snapshot.Whys) == 0 {
+		return nil
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit("Intentions").Clauses(clause.OnConflict{
+			UpdateAll: true,
+		}).Create(&snapshot.Whys).Error
+		if err != nil {
+			return err
+		}
+		if len(snapshot.Intentions) > 0 {
+			if err := restoreIntentions(tx, snapshot.Intentions); err != nil {
+				return err
+			}
+		}
+		for _, day := range snapshot.Days {
+			if err := tx.Omit("Why").Create(&day).Error; err != nil {
+				return err
+			}
+		}
+		if len(snapshot.Reviews) > 0 {
+			err := tx.Omit("Why").Clauses(clause.OnConflict{
+				UpdateAll: true,
+			}).Create(&snapshot.Reviews).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, link := range snapshot.RecurringLinks {
+			err := tx.Exec("INSERT OR IGNORE INTO whys_recurring_intentions (recurring_intention_id, why_id) VALUES (?, ?)", link[0], link[1]).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, child := range snapshot.Children {
+			if err := tx.Model(&Why{}).Where("id = ?", child.ID).Update("parent_id", child.ParentID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
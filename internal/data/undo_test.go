@@ -0,0 +1,101 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+)
+
+// exported returns what store holds, for comparing with what it held before
+// a change was undone
+func exported(t *testing.T, store Store) Export {
+	t.Helper()
+	doc, err := store.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return comparable(doc)
+}
+
+func TestRestoreIntentions(t *testing.T) {
+	store := newTestStore(t)
+	fillStore(t, store)
+	want := exported(t, store)
+
+	day := date(t, "2026-10-15")
+	intentions, err := store.GetDaysIntentions(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := store.GetIntentionsByID([]uint{intentions[0].ID, intentions[1].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 2 {
+		t.Fatalf("got %d intentions by ID, want 2", len(before))
+	}
+
+	// one is changed and unlinked from a goal, the other deleted
+	intentions[0].Content, intentions[0].Done = "w) draft again", false
+	intentions[0].Whys = intentions[0].Whys[:1]
+	if err := store.UpsertIntentions(intentions[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteIntentions(intentions[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(exported(t, store), want) {
+		t.Fatal("changing intentions left the store as it was")
+	}
+
+	if err := store.RestoreIntentions(before); err != nil {
+		t.Fatal(err)
+	}
+	if got := exported(t, store); !reflect.DeepEqual(got, want) {
+		t.Errorf("store after restoring intentions differs:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSnapshotRestoreWhys(t *testing.T) {
+	store := newTestStore(t)
+	fillStore(t, store)
+	want := exported(t, store)
+
+	// Work has a sub-goal, intentions, a day's reflection and a review, and
+	// Health a recurring intention
+	whys, err := store.GetWhys(All)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var deleted []Why
+	for _, why := range whys {
+		if why.Name == "Work" || why.Name == "Health" {
+			deleted = append(deleted, why)
+		}
+	}
+	snapshot, err := store.SnapshotWhys(deleted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Whys) != 2 || len(snapshot.Days) != 1 || len(snapshot.Reviews) != 1 ||
+		len(snapshot.RecurringLinks) != 1 || len(snapshot.Children) != 1 {
+		t.Errorf("snapshot %+v is missing what is deleted with the goals", snapshot)
+	}
+	if err := store.DeleteWhys(deleted); err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(exported(t, store), want) {
+		t.Fatal("deleting goals left the store as it was")
+	}
+
+	if err := store.RestoreWhys(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if got := exported(t, store); !reflect.DeepEqual(got, want) {
+		t.Errorf("store after restoring goals differs:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	// an empty snapshot restores nothing
+	if err := store.RestoreWhys(WhysSnapshot{}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,127 @@
+// Package todotxt reads and writes tasks in the todo.txt format described at
+// https://github.com/todotxt/todo.txt.
+package todotxt
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dateLayout is the format of dates in todo.txt lines
+const dateLayout = "2006-01-02"
+
+// Task is a single line of a todo.txt file
+type Task struct {
+	Done     bool
+	Priority string
+	// Completed and Created are zero when the line doesn't give them
+	Completed time.Time
+	Created   time.Time
+
+	// Text is the description with its projects and key/value tags removed.
+	// Contexts are left in place as they have no special meaning to goalie.
+	Text     string
+	Projects []string
+	Tags     map[string]string
+}
+
+var (
+	priorityRegex = regexp.MustCompile(`^\([A-Z]\)$`)
+	// tag keys must begin with a letter so that times like 10:30 and URLs
+	// aren't mistaken for tags
+	tagKeyRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+)
+
+// Parse parses a todo.txt line, reading dates in loc
+func Parse(line string, loc *time.Location) (Task, error) {
+	task := Task{Tags: make(map[string]string)}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return task, errors.New("empty task")
+	}
+
+	if fields[0] == "x" {
+		task.Done = true
+		fields = fields[1:]
+	}
+	if len(fields) > 0 && priorityRegex.MatchString(fields[0]) {
+		task.Priority = fields[0][1:2]
+		fields = fields[1:]
+	}
+	// A completed task may have a completion date followed by a creation
+	// date; any other task may only have a creation date
+	var dates []time.Time
+	for len(fields) > 0 && len(dates) < 2 {
+		date, err := time.ParseInLocation(dateLayout, fields[0], loc)
+		if err != nil {
+			break
+		}
+		dates = append(dates, date)
+		fields = fields[1:]
+	}
+	switch {
+	case len(dates) == 2:
+		task.Completed, task.Created = dates[0], dates[1]
+	case len(dates) == 1 && task.Done:
+		task.Completed = dates[0]
+	case len(dates) == 1:
+		task.Created = dates[0]
+	}
+
+	var text []string
+	for _, f := range fields {
+		if len(f) > 1 && f[0] == '+' {
+			task.Projects = append(task.Projects, f[1:])
+			continue
+		}
+		if k, v, found := strings.Cut(f, ":"); found && tagKeyRegex.MatchString(k) && v != "" && !strings.ContainsAny(v, ":/") {
+			task.Tags[k] = v
+			continue
+		}
+		text = append(text, f)
+	}
+	task.Text = strings.Join(text, " ")
+	if task.Text == "" {
+		return task, errors.New("task has no description")
+	}
+	return task, nil
+}
+
+// String formats the task as a todo.txt line. Tags are written in
+// alphabetical order.
+func (t Task) String() string {
+	var fields []string
+	if t.Done {
+		fields = append(fields, "x")
+	}
+	if t.Priority != "" {
+		fields = append(fields, "("+t.Priority+")")
+	}
+	if t.Done && !t.Completed.IsZero() {
+		fields = append(fields, t.Completed.Format(dateLayout))
+	}
+	if !t.Created.IsZero() {
+		fields = append(fields, t.Created.Format(dateLayout))
+	}
+	fields = append(fields, t.Text)
+	for _, p := range t.Projects {
+		fields = append(fields, "+"+p)
+	}
+	var keys []string
+	for k := range t.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fields = append(fields, k+":"+t.Tags[k])
+	}
+	return strings.Join(fields, " ")
+}
+
+// Project converts a name, which may contain spaces, into a project name
+func Project(name string) string {
+	return strings.Join(strings.Fields(name), "_")
+}
@@ -0,0 +1,85 @@
+package todotxt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	d, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line string
+		want Task
+	}{
+		{"write report", Task{Text: "write report"}},
+		{"(A) 2026-10-15 write report +Work @desk", Task{
+			Priority: "A", Created: day("2026-10-15"),
+			Text: "write report @desk", Projects: []string{"Work"},
+		}},
+		{"x 2026-10-16 2026-10-15 write report pomo:2", Task{
+			Done: true, Completed: day("2026-10-16"), Created: day("2026-10-15"),
+			Text: "write report", Tags: map[string]string{"pomo": "2"},
+		}},
+		{"x 2026-10-16 write report", Task{
+			Done: true, Completed: day("2026-10-16"), Text: "write report",
+		}},
+		{"call at 10:30 about https://example.com", Task{
+			Text: "call at 10:30 about https://example.com",
+		}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.line, time.UTC)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.line, err)
+			continue
+		}
+		if tt.want.Tags == nil {
+			tt.want.Tags = map[string]string{}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, line := range []string{"", "   ", "x 2026-10-16", "+Work pomo:2"} {
+		if _, err := Parse(line, time.UTC); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", line)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	lines := []string{
+		"write report",
+		"(B) 2026-10-15 write report +Work",
+		"x 2026-10-16 2026-10-15 write report +Work +Side pomo:2 z:last",
+		"x 2026-10-16 done without a creation date",
+		"2026-10-15 call bob (work) @phone",
+	}
+	for _, line := range lines {
+		task, err := Parse(line, time.UTC)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", line, err)
+			continue
+		}
+		if got := task.String(); got != line {
+			t.Errorf("Parse(%q).String() = %q", line, got)
+		}
+	}
+}
+
+func TestProject(t *testing.T) {
+	if got := Project("  Learn  Go "); got != "Learn_Go" {
+		t.Errorf("Project = %q, want Learn_Go", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/benhsm/goalie/internal/config"
 	"github.com/benhsm/goalie/internal/data"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -29,23 +30,36 @@ type Component interface {
 	SetSize(height, width int)
 }
 
+// Typer is implemented by pages which can be typing into an input, or
+// answering a prompt, during which keys go to the page rather than being
+// global bindings
+type Typer interface {
+	Typing() bool
+}
+
 // Common is a struct all components should embed
 type Common struct {
 	Width      int
 	Height     int
 	Zone       *zone.Manager
 	Store      data.Store
+	Config     config.Config
+	Keys       *Keymap
+	History    *History
 	Figlet     *figlet4go.AsciiRender
 	FigletOpts *figlet4go.RenderOptions
 }
 
-func NewCommon() Common {
+func NewCommon(store data.Store, cfg config.Config) Common {
 	figlet := figlet4go.NewAsciiRender()
 	figletOpts := figlet4go.NewRenderOptions()
 	figlet.LoadBindataFont(fontFuture, "future")
 	figletOpts.FontName = "future"
 	return Common{
-		Store:      data.NewStore(),
+		Store:      store,
+		Config:     cfg,
+		Keys:       NewKeymap(cfg.Keys),
+		History:    NewHistory(),
 		Figlet:     figlet,
 		FigletOpts: figletOpts,
 	}
@@ -57,13 +71,21 @@ func (c *Common) SetSize(height, width int) {
 	c.Height = height
 }
 
+// CurrentDay returns midnight of the day the user is currently living in
+func (c *Common) CurrentDay() time.Time {
+	return c.Config.Day.CurrentDay()
+}
+
 // Commands providing an interface between the tui and the data layer
 
 type ErrMsg struct{ Error error }
 
 type WhyDataMsg struct {
-	Data  []data.Why
-	Error error
+	Data []data.Why
+	// Status is the filter the goals were read with. Only active goals are
+	// given to every page.
+	Status data.WhyStatusEnum
+	Error  error
 }
 
 func (c *Common) ReadWhys(filter data.WhyStatusEnum) tea.Cmd {
@@ -73,22 +95,138 @@ func (c *Common) ReadWhys(filter data.WhyStatusEnum) tea.Cmd {
 			return res[i].Number < res[j].Number
 		})
 		return WhyDataMsg{
-			Data:  res,
-			Error: err,
+			Data:   res,
+			Status: filter,
+			Error:  err,
 		}
 	}
 }
 
+// UpsertWhys saves whys, recording the change in the history
 func (c *Common) UpsertWhys(whys []data.Why) tea.Cmd {
 	return func() tea.Msg {
-		err := c.Store.UpsertWhys(whys)
+		change, err := c.upsertWhys(whys)
+		if err == nil {
+			c.History.record(change)
+		}
 		return ErrMsg{err}
 	}
 }
 
+// DeleteWhys deletes whys, recording the change in the history
 func (c *Common) DeleteWhys(whys []data.Why) tea.Cmd {
 	return func() tea.Msg {
-		err := c.Store.DeleteWhys(whys)
+		if len(whys) == 0 {
+			return ErrMsg{}
+		}
+		change, err := c.deleteWhys(whys)
+		if err == nil {
+			c.History.record(change)
+		}
+		return ErrMsg{err}
+	}
+}
+
+// SyncWhys saves changed and deletes deleted, recording both as one change
+// in the history
+func (c *Common) SyncWhys(changed, deleted []data.Why) tea.Cmd {
+	return func() tea.Msg {
+		var changes []change
+		if len(changed) > 0 {
+			change, err := c.upsertWhys(changed)
+			if err != nil {
+				return ErrMsg{err}
+			}
+			changes = append(changes, change)
+		}
+		if len(deleted) > 0 {
+			change, err := c.deleteWhys(deleted)
+			if err != nil {
+				return ErrMsg{err}
+			}
+			changes = append(changes, change)
+		}
+		c.History.record(combine("changes to goals", changes...))
+		return ErrMsg{}
+	}
+}
+
+// ProgressMsg holds the progress of every goal towards its target, by ID
+type ProgressMsg struct {
+	Data  map[uint]data.Progress
+	Error error
+}
+
+func (c *Common) GetProgress() tea.Cmd {
+	return func() tea.Msg {
+		progress, err := c.Store.GetProgress()
+		return ProgressMsg{Data: progress, Error: err}
+	}
+}
+
+// TimerTickMsg is sent every second while a pomodoro timer is running. It is
+// delivered to the today page whichever page is active, so that the timer
+// keeps running in the background.
+type TimerTickMsg struct {
+	ID   int
+	Time time.Time
+}
+
+// TimerSavedMsg reports whether what a pomodoro changed was saved. Like
+// TimerTickMsg it is delivered to the today page only, since a pomodoro may
+// end while another page, with unsaved changes of its own, is active.
+type TimerSavedMsg struct{ Error error }
+
+// TimerSaved returns cmd, which saves what a pomodoro changed, reporting its
+// result as a TimerSavedMsg rather than an ErrMsg
+func TimerSaved(cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		msg := cmd()
+		if msg, ok := msg.(ErrMsg); ok {
+			return TimerSavedMsg{msg.Error}
+		}
+		return msg
+	}
+}
+
+// DeleteIntentions deletes intentions, recording the change in the history
+func (c *Common) DeleteIntentions(intentions []data.Intention) tea.Cmd {
+	return func() tea.Msg {
+		change, err := c.deleteIntentions(intentions)
+		if err == nil {
+			c.History.record(change)
+		}
+		return ErrMsg{err}
+	}
+}
+
+// SyncIntentions deletes deleted and saves changed, recording both as one
+// change in the history
+func (c *Common) SyncIntentions(changed, deleted []data.Intention) tea.Cmd {
+	return func() tea.Msg {
+		var changes []change
+		if len(deleted) > 0 {
+			change, err := c.deleteIntentions(deleted)
+			if err != nil {
+				return ErrMsg{err}
+			}
+			changes = append(changes, change)
+		}
+		if len(changed) > 0 {
+			change, err := c.upsertIntentions(changed)
+			if err != nil {
+				return ErrMsg{err}
+			}
+			changes = append(changes, change)
+		}
+		c.History.record(combine("changes to intentions", changes...))
+		return ErrMsg{}
+	}
+}
+
+func (c *Common) AddPomoSession(session data.PomoSession) tea.Cmd {
+	return func() tea.Msg {
+		err := c.Store.AddPomoSession(session)
 		return ErrMsg{err}
 	}
 }
@@ -97,12 +235,18 @@ type IntentionMsg struct {
 	Yesterday []data.Intention
 	Today     []data.Intention
 	Tomorrow  []data.Intention
+	// Recurring holds every recurring intention, whether or not it is due
+	Recurring []data.RecurringIntention
 	Error     error
 }
 
+// UpsertIntentions saves intentions, recording the change in the history
 func (c *Common) UpsertIntentions(intentions []data.Intention) tea.Cmd {
 	return func() tea.Msg {
-		err := c.Store.UpsertIntentions(intentions)
+		change, err := c.upsertIntentions(intentions)
+		if err == nil {
+			c.History.record(change)
+		}
 		return ErrMsg{err}
 	}
 }
@@ -124,18 +268,197 @@ func (c *Common) GetDaysIntentions(day time.Time) tea.Cmd {
 			})
 			res[i] = data
 		}
+		recurring, err := c.Store.GetRecurringIntentions()
+		if err != nil {
+			return IntentionMsg{Error: err}
+		}
 		return IntentionMsg{
 			Yesterday: res[0],
 			Today:     res[1],
 			Tomorrow:  res[2],
+			Recurring: recurring,
 			Error:     nil,
 		}
 	}
 }
 
+// PlannedMsg holds the intentions planned ahead for Date which haven't been
+// confirmed yet, ordered by position
+type PlannedMsg struct {
+	Date       time.Time
+	Intentions []data.Intention
+	Error      error
+}
+
+// GetPlanned reads the intentions planned ahead for day
+func (c *Common) GetPlanned(day time.Time) tea.Cmd {
+	return func() tea.Msg {
+		intentions, err := c.Store.GetDaysIntentions(day)
+		if err != nil {
+			return PlannedMsg{Date: day, Error: err}
+		}
+		var planned []data.Intention
+		for _, i := range intentions {
+			if i.Planned {
+				planned = append(planned, i)
+			}
+		}
+		sort.Slice(planned, func(i, j int) bool {
+			return planned[i].Position < planned[j].Position
+		})
+		return PlannedMsg{Date: day, Intentions: planned}
+	}
+}
+
 func (c *Common) UpsertDayReview(days []data.Day) tea.Cmd {
 	return func() tea.Msg {
 		err := c.Store.UpsertDayReview(days)
 		return ErrMsg{err}
 	}
 }
+
+type DayReviewMsg struct {
+	Date  time.Time
+	Days  []data.Day
+	Error error
+}
+
+// GetDayReviews reads the reviews already given for day
+func (c *Common) GetDayReviews(day time.Time) tea.Cmd {
+	return func() tea.Msg {
+		days, err := c.Store.GetDayReviewsBetween(day, day)
+		return DayReviewMsg{Date: day, Days: days, Error: err}
+	}
+}
+
+type TimelineMsg struct {
+	Start      time.Time
+	End        time.Time
+	Intentions []data.Intention
+	Days       []data.Day
+	Sessions   []data.PomoSession
+	Error      error
+}
+
+// GetTimeline reads the intentions and day reviews for every day from start
+// to end, inclusive.
+func (c *Common) GetTimeline(start, end time.Time) tea.Cmd {
+	return func() tea.Msg {
+		intentions, err := c.Store.GetIntentionsBetween(start, end)
+		if err != nil {
+			return TimelineMsg{Error: err}
+		}
+		days, err := c.Store.GetDayReviewsBetween(start, end)
+		if err != nil {
+			return TimelineMsg{Error: err}
+		}
+		sessions, err := c.Store.GetPomoSessionsBetween(start, end)
+		if err != nil {
+			return TimelineMsg{Error: err}
+		}
+		return TimelineMsg{
+			Start:      start,
+			End:        end,
+			Intentions: intentions,
+			Days:       days,
+			Sessions:   sessions,
+		}
+	}
+}
+
+type StatsMsg struct {
+	Start      time.Time
+	End        time.Time
+	Intentions []data.Intention
+	Days       []data.Day
+	Error      error
+}
+
+// GetStats reads the intentions and day reviews for every day from start to
+// end, inclusive, from which the statistics page is computed. Those of
+// sub-goals are rolled up into their top-level goals.
+func (c *Common) GetStats(start, end time.Time) tea.Cmd {
+	return func() tea.Msg {
+		intentions, err := c.Store.GetIntentionsBetween(start, end)
+		if err != nil {
+			return StatsMsg{Error: err}
+		}
+		days, err := c.Store.GetDayReviewsBetween(start, end)
+		if err != nil {
+			return StatsMsg{Error: err}
+		}
+		whys, err := c.Store.GetWhys(data.All)
+		if err != nil {
+			return StatsMsg{Error: err}
+		}
+		intentions, days = data.RollUp(intentions, days, whys)
+		return StatsMsg{
+			Start:      start,
+			End:        end,
+			Intentions: intentions,
+			Days:       days,
+		}
+	}
+}
+
+type ReviewMsg struct {
+	Period     data.Period
+	Start      time.Time
+	End        time.Time
+	Intentions []data.Intention
+	Days       []data.Day
+	Reviews    []data.Review
+	SubReviews []data.Review
+	Error      error
+}
+
+// GetReviewData reads everything needed to review the period from start to
+// end, inclusive, along with any answers already given for it and for the
+// sub-periods it rolls up. Intentions and day reviews of sub-goals are rolled
+// up into their top-level goals.
+func (c *Common) GetReviewData(period data.Period, start, end time.Time) tea.Cmd {
+	return func() tea.Msg {
+		intentions, err := c.Store.GetIntentionsBetween(start, end)
+		if err != nil {
+			return ReviewMsg{Error: err}
+		}
+		days, err := c.Store.GetDayReviewsBetween(start, end)
+		if err != nil {
+			return ReviewMsg{Error: err}
+		}
+		whys, err := c.Store.GetWhys(data.All)
+		if err != nil {
+			return ReviewMsg{Error: err}
+		}
+		intentions, days = data.RollUp(intentions, days, whys)
+		reviews, err := c.Store.GetReviews(period, start)
+		if err != nil {
+			return ReviewMsg{Error: err}
+		}
+		subReviews, err := c.Store.GetSubReviews(period, start, end)
+		if err != nil {
+			return ReviewMsg{Error: err}
+		}
+		return ReviewMsg{
+			Period:     period,
+			Start:      start,
+			End:        end,
+			Intentions: intentions,
+			Days:       days,
+			Reviews:    reviews,
+			SubReviews: subReviews,
+		}
+	}
+}
+
+// ReviewsSavedMsg reports the result of UpsertReviews
+type ReviewsSavedMsg struct {
+	Error error
+}
+
+func (c *Common) UpsertReviews(reviews []data.Review) tea.Cmd {
+	return func() tea.Msg {
+		err := c.Store.UpsertReviews(reviews)
+		return ReviewsSavedMsg{err}
+	}
+}
@@ -0,0 +1,351 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benhsm/goalie/internal/data"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// History records the changes made to the store during the session, each
+// as a pair of functions which reverse and repeat it, so that they can be
+// undone and redone in turn. It is shared by every page.
+type History struct {
+	mu     sync.Mutex
+	done   []change
+	undone []change
+}
+
+type change struct {
+	// name describes the change in the messages about undoing it
+	name string
+	undo func() error
+	redo func() error
+}
+
+func NewHistory() *History {
+	return &History{}
+}
+
+// record adds a change which has just been made, unless it is noChange.
+// Changes which were undone can't be redone after another is made.
+func (h *History) record(c change) {
+	if c.undo == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.done = append(h.done, c)
+	h.undone = nil
+}
+
+// UndoMsg reports that a change was undone, or redone, after which pages
+// read again what they show
+type UndoMsg struct {
+	Name  string
+	Redo  bool
+	Error error
+}
+
+// Undo reverses the last change made which hasn't been undone
+func (c *Common) Undo() tea.Cmd {
+	return func() tea.Msg {
+		h := c.History
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if len(h.done) == 0 {
+			return UndoMsg{Error: errors.New("nothing to undo")}
+		}
+		last := h.done[len(h.done)-1]
+		if err := last.undo(); err != nil {
+			return UndoMsg{Name: last.name, Error: fmt.Errorf("couldn't undo %s: %w", last.name, err)}
+		}
+		h.done = h.done[:len(h.done)-1]
+		h.undone = append(h.undone, last)
+		return UndoMsg{Name: last.name}
+	}
+}
+
+// Redo repeats the last change which was undone
+func (c *Common) Redo() tea.Cmd {
+	return func() tea.Msg {
+		h := c.History
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if len(h.undone) == 0 {
+			return UndoMsg{Redo: true, Error: errors.New("nothing to redo")}
+		}
+		last := h.undone[len(h.undone)-1]
+		if err := last.redo(); err != nil {
+			return UndoMsg{Name: last.name, Redo: true, Error: fmt.Errorf("couldn't redo %s: %w", last.name, err)}
+		}
+		h.undone = h.undone[:len(h.undone)-1]
+		h.done = append(h.done, last)
+		return UndoMsg{Name: last.name, Redo: true}
+	}
+}
+
+// String describes the message for the status line of a page
+func (msg UndoMsg) String() string {
+	switch {
+	case msg.Error != nil:
+		return msg.Error.Error()
+	case msg.Redo:
+		return "redid " + msg.Name
+	}
+	return "undid " + msg.Name
+}
+
+// combine makes one change of several, which are undone in reverse. A
+// single change keeps its own name.
+func combine(name string, changes ...change) change {
+	var made []change
+	for _, c := range changes {
+		if c.undo != nil {
+			made = append(made, c)
+		}
+	}
+	switch len(made) {
+	case 0:
+		return noChange
+	case 1:
+		return made[0]
+	}
+	changes = made
+	return change{
+		name: name,
+		undo: func() error {
+			for i := len(changes) - 1; i >= 0; i-- {
+				if err := changes[i].undo(); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		redo: func() error {
+			for _, c := range changes {
+				if err := c.redo(); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// noChange is returned when nothing was changed, and isn't recorded
+var noChange change
+
+// upsertIntentions saves intentions, returning how to put back what they
+// replaced. Intentions which didn't exist before are deleted when undoing.
+func (c *Common) upsertIntentions(intentions []data.Intention) (change, error) {
+	var ids []uint
+	for _, i := range intentions {
+		if i.ID != 0 {
+			ids = append(ids, i.ID)
+		}
+	}
+	before, err := c.Store.GetIntentionsByID(ids)
+	if err != nil {
+		return noChange, err
+	}
+	if err := c.Store.UpsertIntentions(intentions); err != nil {
+		return noChange, err
+	}
+
+	after := append([]data.Intention{}, intentions...)
+	existed := make(map[uint]data.Intention)
+	for _, i := range before {
+		existed[i.ID] = i
+	}
+	// pages save a whole day's intentions when one of them changes, but
+	// only those which changed are counted
+	var created []data.Intention
+	changed := 0
+	for _, i := range after {
+		old, ok := existed[i.ID]
+		if !ok {
+			created = append(created, i)
+		}
+		if !ok || !sameIntention(old, i) {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return noChange, nil
+	}
+	return change{
+		name: "changes to " + count(changed, "intention"),
+		undo: func() error {
+			if err := c.Store.DeleteIntentions(created); err != nil {
+				return err
+			}
+			return c.Store.RestoreIntentions(before)
+		},
+		redo: func() error {
+			return c.Store.RestoreIntentions(after)
+		},
+	}, nil
+}
+
+// sameIntention reports whether a and b are the same intention, unchanged
+func sameIntention(a, b data.Intention) bool {
+	return a.ID == b.ID && data.DateOf(a.Date).Equal(data.DateOf(b.Date)) &&
+		a.Content == b.Content && a.Done == b.Done && a.Cancelled == b.Cancelled &&
+		a.Outcome == b.Outcome && a.Unintended == b.Unintended &&
+		a.Position == b.Position && a.Pomos == b.Pomos &&
+		a.Interruptions == b.Interruptions && a.Planned == b.Planned
+}
+
+// upsertWhys saves whys, returning how to put back what they replaced. Goals
+// which didn't exist before are deleted when undoing, and restored with what
+// was deleted with them when redoing.
+func (c *Common) upsertWhys(whys []data.Why) (change, error) {
+	var ids []uint
+	for _, why := range whys {
+		if why.ID != 0 {
+			ids = append(ids, why.ID)
+		}
+	}
+	before, err := c.Store.GetWhysByID(ids)
+	if err != nil {
+		return noChange, err
+	}
+	if err := c.Store.UpsertWhys(whys); err != nil {
+		return noChange, err
+	}
+
+	after := append([]data.Why{}, whys...)
+	existed := make(map[uint]data.Why)
+	for _, why := range before {
+		existed[why.ID] = why
+	}
+	// the goals page saves every goal when one of them changes, but only
+	// those which changed are counted
+	var created []data.Why
+	changed := 0
+	for _, why := range after {
+		old, ok := existed[why.ID]
+		if !ok {
+			created = append(created, why)
+		}
+		if !ok || !sameWhy(old, why) {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return noChange, nil
+	}
+	var snapshot data.WhysSnapshot
+	return change{
+		name: "changes to " + count(changed, "goal"),
+		undo: func() error {
+			var err error
+			snapshot, err = c.Store.SnapshotWhys(created)
+			if err != nil {
+				return err
+			}
+			if err := c.Store.DeleteWhys(created); err != nil {
+				return err
+			}
+			if len(before) == 0 {
+				return nil
+			}
+			return c.Store.UpsertWhys(before)
+		},
+		redo: func() error {
+			if err := c.Store.RestoreWhys(snapshot); err != nil {
+				return err
+			}
+			return c.Store.UpsertWhys(after)
+		},
+	}, nil
+}
+
+// sameWhy reports whether a and b are the same goal, unchanged
+func sameWhy(a, b data.Why) bool {
+	return a.ID == b.ID && a.Name == b.Name && a.Description == b.Description &&
+		a.Code == b.Code && a.Number == b.Number && a.Color == b.Color &&
+		a.Archived == b.Archived && sameID(a.ParentID, b.ParentID) &&
+		sameDate(a.StartDate, b.StartDate) && sameDate(a.TargetDate, b.TargetDate) &&
+		a.Target == b.Target && a.Measure == b.Measure
+}
+
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return data.DateOf(*a).Equal(data.DateOf(*b))
+}
+
+// deleteWhys deletes whys, returning how to restore them along with what was
+// deleted with them
+func (c *Common) deleteWhys(whys []data.Why) (change, error) {
+	snapshot, err := c.Store.SnapshotWhys(whys)
+	if err != nil {
+		return noChange, err
+	}
+	if err := c.Store.DeleteWhys(whys); err != nil {
+		return noChange, err
+	}
+	if len(snapshot.Whys) == 0 {
+		return noChange, nil
+	}
+	return change{
+		name: "deleting " + count(len(snapshot.Whys), "goal"),
+		undo: func() error {
+			return c.Store.RestoreWhys(snapshot)
+		},
+		redo: func() error {
+			return c.Store.DeleteWhys(snapshot.Whys)
+		},
+	}, nil
+}
+
+// deleteIntentions deletes intentions, returning how to restore them as they
+// were
+func (c *Common) deleteIntentions(intentions []data.Intention) (change, error) {
+	var ids []uint
+	for _, i := range intentions {
+		if i.ID != 0 {
+			ids = append(ids, i.ID)
+		}
+	}
+	before, err := c.Store.GetIntentionsByID(ids)
+	if err != nil {
+		return noChange, err
+	}
+	if err := c.Store.DeleteIntentions(before); err != nil {
+		return noChange, err
+	}
+	if len(before) == 0 {
+		return noChange, nil
+	}
+	return change{
+		name: "deleting " + count(len(before), "intention"),
+		undo: func() error {
+			return c.Store.RestoreIntentions(before)
+		},
+		redo: func() error {
+			return c.Store.DeleteIntentions(before)
+		},
+	}, nil
+}
+
+// count returns n things, as in "1 goal" or "2 goals"
+func count(n int, thing string) string {
+	if n == 1 {
+		return "1 " + thing
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
@@ -0,0 +1,227 @@
+package common
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benhsm/goalie/internal/config"
+	"github.com/benhsm/goalie/internal/data"
+)
+
+// counter is changed by the changes made in tests of the history alone
+type counter struct {
+	n   int
+	log []string
+}
+
+// add returns a change which adds n to the counter
+func (c *counter) add(name string, n int) change {
+	c.n += n
+	return change{
+		name: name,
+		undo: func() error {
+			c.n -= n
+			c.log = append(c.log, "undo "+name)
+			return nil
+		},
+		redo: func() error {
+			c.n += n
+			c.log = append(c.log, "redo "+name)
+			return nil
+		},
+	}
+}
+
+func TestHistory(t *testing.T) {
+	c := Common{History: NewHistory()}
+	var ctr counter
+	undo := func() UndoMsg { return c.Undo()().(UndoMsg) }
+	redo := func() UndoMsg { return c.Redo()().(UndoMsg) }
+
+	if msg := undo(); msg.Error == nil {
+		t.Error("undid something in an empty history")
+	}
+	c.History.record(ctr.add("one", 1))
+	c.History.record(noChange)
+	c.History.record(ctr.add("two", 2))
+
+	if msg := undo(); msg.Error != nil || msg.Name != "two" || ctr.n != 1 {
+		t.Errorf("undo gave %+v, counter %d, want two undone leaving 1", msg, ctr.n)
+	}
+	if msg := undo(); msg.Error != nil || msg.Name != "one" || ctr.n != 0 {
+		t.Errorf("undo gave %+v, counter %d, want one undone leaving 0", msg, ctr.n)
+	}
+	if msg := undo(); msg.Error == nil {
+		t.Error("undid more than was recorded")
+	}
+	if msg := redo(); msg.Error != nil || msg.Name != "one" || !msg.Redo || ctr.n != 1 {
+		t.Errorf("redo gave %+v, counter %d, want one redone leaving 1", msg, ctr.n)
+	}
+
+	// making another change forgets what was undone
+	c.History.record(ctr.add("three", 3))
+	if msg := redo(); msg.Error == nil {
+		t.Error("redid a change undone before another was made")
+	}
+	if msg := undo(); msg.Name != "three" || ctr.n != 1 {
+		t.Errorf("undo gave %+v, counter %d, want three undone leaving 1", msg, ctr.n)
+	}
+}
+
+func TestHistoryFailing(t *testing.T) {
+	c := Common{History: NewHistory()}
+	c.History.record(change{
+		name: "broken",
+		undo: func() error { return errors.New("locked") },
+		redo: func() error { return nil },
+	})
+	msg := c.Undo()().(UndoMsg)
+	if msg.Error == nil || msg.String() != "couldn't undo broken: locked" {
+		t.Errorf("undo gave %q, want it to fail", msg)
+	}
+	// what couldn't be undone can be tried again
+	if len(c.History.done) != 1 || len(c.History.undone) != 0 {
+		t.Errorf("failing to undo moved the change: %+v", c.History)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	if got := combine("nothing", noChange, noChange); got.undo != nil {
+		t.Error("combining no changes made a change")
+	}
+	var ctr counter
+	if got := combine("one", noChange, ctr.add("add", 1)); got.name != "add" {
+		t.Errorf("a single change combined is named %q, want add", got.name)
+	}
+	ctr = counter{}
+	both := combine("both", ctr.add("one", 1), noChange, ctr.add("two", 2))
+	if err := both.undo(); err != nil || ctr.n != 0 {
+		t.Errorf("undoing both gave %v, counter %d", err, ctr.n)
+	}
+	if err := both.redo(); err != nil || ctr.n != 3 {
+		t.Errorf("redoing both gave %v, counter %d", err, ctr.n)
+	}
+	want := []string{"undo two", "undo one", "redo one", "redo two"}
+	if !reflect.DeepEqual(ctr.log, want) {
+		t.Errorf("changes were made in the order %q, want %q", ctr.log, want)
+	}
+}
+
+// newTestCommon returns a Common using a store in a temporary directory
+// which holds a goal with two intentions on 2026-10-15
+func newTestCommon(t *testing.T) (Common, []data.Intention) {
+	t.Helper()
+	store, err := data.NewStore(filepath.Join(t.TempDir(), "goalie.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	whys := []data.Why{{Name: "Work", Code: "w"}}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	intentions := []data.Intention{
+		{Date: day, Content: "w) draft", Whys: []*data.Why{&whys[0]}},
+		{Date: day, Content: "w) send", Position: 1, Whys: []*data.Why{&whys[0]}},
+	}
+	if err := store.UpsertIntentions(intentions); err != nil {
+		t.Fatal(err)
+	}
+	return NewCommon(store, config.Default()), intentions
+}
+
+// contents returns the contents of the intentions saved for day
+func contents(t *testing.T, c Common, day time.Time) []string {
+	t.Helper()
+	intentions, err := c.Store.GetDaysIntentions(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result []string
+	for _, i := range intentions {
+		result = append(result, i.Content+" "+data.Prefix(i.Whys))
+	}
+	return result
+}
+
+func TestUndoIntentions(t *testing.T) {
+	c, intentions := newTestCommon(t)
+	day := intentions[0].Date
+	want := contents(t, c, day)
+
+	// one is changed and another added, as the today page saves them
+	changed := append([]data.Intention{}, intentions...)
+	changed[0].Content = "w) redraft"
+	changed = append(changed, data.Intention{Date: day, Content: "&) rest", Position: 2})
+	if msg := c.UpsertIntentions(changed)(); msg.(ErrMsg).Error != nil {
+		t.Fatal(msg)
+	}
+	after := contents(t, c, day)
+
+	// saving them again unchanged isn't a change
+	saved, err := c.Store.GetDaysIntentions(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.UpsertIntentions(saved)()
+	if len(c.History.done) != 1 {
+		t.Fatalf("recorded %d changes, want 1", len(c.History.done))
+	}
+
+	if msg := c.Undo()().(UndoMsg); msg.Error != nil || msg.Name != "changes to 2 intentions" {
+		t.Errorf("undo gave %+v", msg)
+	}
+	if got := contents(t, c, day); !reflect.DeepEqual(got, want) {
+		t.Errorf("after undoing got %q, want %q", got, want)
+	}
+	c.Redo()()
+	if got := contents(t, c, day); !reflect.DeepEqual(got, after) {
+		t.Errorf("after redoing got %q, want %q", got, after)
+	}
+}
+
+func TestUndoDeleteIntentions(t *testing.T) {
+	c, intentions := newTestCommon(t)
+	day := intentions[0].Date
+	want := contents(t, c, day)
+
+	if msg := c.DeleteIntentions(intentions[1:])(); msg.(ErrMsg).Error != nil {
+		t.Fatal(msg)
+	}
+	if got := contents(t, c, day); len(got) != 1 {
+		t.Fatalf("got %q after deleting one of two intentions", got)
+	}
+	if msg := c.Undo()().(UndoMsg); msg.Error != nil || msg.Name != "deleting 1 intention" {
+		t.Errorf("undo gave %+v", msg)
+	}
+	if got := contents(t, c, day); !reflect.DeepEqual(got, want) {
+		t.Errorf("after undoing got %q, want %q", got, want)
+	}
+	c.Redo()()
+	if got := contents(t, c, day); len(got) != 1 {
+		t.Errorf("got %q after redoing the deletion", got)
+	}
+}
+
+func TestUndoSyncIntentions(t *testing.T) {
+	c, intentions := newTestCommon(t)
+	day := intentions[0].Date
+	want := contents(t, c, day)
+
+	// deleting one and changing the other is undone at once
+	kept := intentions[0]
+	kept.Content = "w) redraft"
+	if msg := c.SyncIntentions([]data.Intention{kept}, intentions[1:])(); msg.(ErrMsg).Error != nil {
+		t.Fatal(msg)
+	}
+	if len(c.History.done) != 1 {
+		t.Fatalf("recorded %d changes, want 1", len(c.History.done))
+	}
+	c.Undo()()
+	if got := contents(t, c, day); !reflect.DeepEqual(got, want) {
+		t.Errorf("after undoing got %q, want %q", got, want)
+	}
+}
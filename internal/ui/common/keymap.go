@@ -0,0 +1,120 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// GlobalKeys is the name under which keys handled regardless of the active
+// page are registered. They must not conflict with the keys of any page.
+const GlobalKeys = "global"
+
+// Keymap is a registry of the actions of every page and the keys bound to
+// them. Pages register their keymaps when they are created, which applies the
+// user's configured bindings and lets conflicts be detected at startup.
+type Keymap struct {
+	config map[string]map[string][]string
+	pages  map[string][]action
+}
+
+type action struct {
+	name    string
+	binding key.Binding
+}
+
+func NewKeymap(config map[string]map[string][]string) *Keymap {
+	return &Keymap{
+		config: config,
+		pages:  make(map[string][]action),
+	}
+}
+
+// Register overrides the bindings in keymap, which must be a pointer to a
+// struct of key.Bindings, with any configured for page, and records them.
+// Actions are named by the snake_case form of the struct's field names, so
+// MarkDone is mark_done. The help for remapped actions shows their new keys.
+func (k *Keymap) Register(page string, keymap any) {
+	v := reflect.ValueOf(keymap).Elem()
+	var actions []action
+	for i := 0; i < v.NumField(); i++ {
+		binding, ok := v.Field(i).Addr().Interface().(*key.Binding)
+		if !ok {
+			continue
+		}
+		name := snakeCase(v.Type().Field(i).Name)
+		if keys, ok := k.config[page][name]; ok {
+			binding.SetKeys(keys...)
+			binding.SetHelp(strings.Join(keys, "/"), binding.Help().Desc)
+		}
+		actions = append(actions, action{name, *binding})
+	}
+	k.pages[page] = actions
+}
+
+// Problems describes each configured action that no page has registered, and
+// each key which is bound to more than one action on the same page or to both
+// a page action and a global one.
+func (k *Keymap) Problems() []string {
+	var result []string
+	for page, actions := range k.config {
+		for name := range actions {
+			if !k.has(page, name) {
+				result = append(result, fmt.Sprintf("unknown key action %q", "keys."+page+"."+name))
+			}
+		}
+	}
+
+	for page, actions := range k.pages {
+		bound := make(map[string][]string)
+		for _, a := range actions {
+			for _, s := range a.binding.Keys() {
+				bound[s] = append(bound[s], page+"."+a.name)
+			}
+		}
+		if page != GlobalKeys {
+			for _, a := range k.pages[GlobalKeys] {
+				for _, s := range a.binding.Keys() {
+					if len(bound[s]) > 0 {
+						bound[s] = append(bound[s], GlobalKeys+"."+a.name)
+					}
+				}
+			}
+		}
+		for s, names := range bound {
+			if len(names) > 1 {
+				result = append(result, fmt.Sprintf("key %q is bound to more than one action: %s",
+					s, strings.Join(names, ", ")))
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (k *Keymap) has(page, name string) bool {
+	for _, a := range k.pages[page] {
+		if a.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
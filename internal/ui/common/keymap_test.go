@@ -0,0 +1,71 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+type testKeys struct {
+	MarkDone key.Binding
+	Quit     key.Binding
+}
+
+func newTestKeys() testKeys {
+	return testKeys{
+		MarkDone: key.NewBinding(key.WithKeys("space"), key.WithHelp("space", "mark done")),
+		Quit:     key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
+	}
+}
+
+func TestKeymapRegister(t *testing.T) {
+	k := NewKeymap(map[string]map[string][]string{"today": {"mark_done": {"d", "ctrl+d"}}})
+	keys := newTestKeys()
+	k.Register("today", &keys)
+	if got := keys.MarkDone.Keys(); !reflect.DeepEqual(got, []string{"d", "ctrl+d"}) {
+		t.Errorf("mark_done is bound to %q, want d and ctrl+d", got)
+	}
+	if help := keys.MarkDone.Help(); help.Key != "d/ctrl+d" || help.Desc != "mark done" {
+		t.Errorf("help for mark_done is %+v", help)
+	}
+	// actions which aren't configured keep their keys
+	if got := keys.Quit.Keys(); !reflect.DeepEqual(got, []string{"q"}) {
+		t.Errorf("quit is bound to %q, want q", got)
+	}
+	if problems := k.Problems(); len(problems) != 0 {
+		t.Errorf("got problems %q, want none", problems)
+	}
+}
+
+func TestKeymapProblems(t *testing.T) {
+	k := NewKeymap(map[string]map[string][]string{
+		"today":  {"mark_done": {"q"}, "mark_undone": {"u"}},
+		"stats":  {"quit": {"ctrl+r"}},
+		"nobody": {"quit": {"x"}},
+	})
+	today, stats, global := newTestKeys(), newTestKeys(), struct{ Redo key.Binding }{
+		key.NewBinding(key.WithKeys("ctrl+r")),
+	}
+	k.Register("today", &today)
+	k.Register("stats", &stats)
+	k.Register(GlobalKeys, &global)
+
+	want := []string{
+		`key "ctrl+r" is bound to more than one action: stats.quit, global.redo`,
+		`key "q" is bound to more than one action: today.mark_done, today.quit`,
+		`unknown key action "keys.nobody.quit"`,
+		`unknown key action "keys.today.mark_undone"`,
+	}
+	if got := k.Problems(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got problems:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for s, want := range map[string]string{"Quit": "quit", "MarkDone": "mark_done", "NextPeriod": "next_period"} {
+		if got := snakeCase(s); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
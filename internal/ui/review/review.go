@@ -0,0 +1,578 @@
+package review
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/benhsm/goalie/internal/data"
+	"github.com/benhsm/goalie/internal/ui/common"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	docStyle    = lipgloss.NewStyle().Margin(1, 2)
+	promptStyle = lipgloss.NewStyle().Bold(true)
+	titleStyle  = func(color lipgloss.Color) lipgloss.Style {
+		return lipgloss.NewStyle().
+			Background(color).
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Bold(true).Padding(0, 1, 0, 1)
+	}
+	dimStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#969B86", Dark: "#696969"})
+	selectedStyle   = lipgloss.NewStyle().Bold(true)
+	reflectionStyle = lipgloss.NewStyle().Padding(0, 0, 0, 2)
+	sectionStyle    = func(color lipgloss.Color, width int) lipgloss.Style {
+		return lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder(), true).
+			BorderForeground(color).
+			Width(width).
+			Padding(0, 1)
+	}
+	miscColor = lipgloss.Color("#808080")
+)
+
+// questions are asked of every goal at the end of a review period. Their
+// answers are stored in the fields returned by answers, in the same order.
+var questions = []string{
+	"What progress did you make?",
+	"What got in the way?",
+	"What will you focus on next?",
+}
+
+func answers(r *data.Review) []*string {
+	return []*string{&r.Progress, &r.Obstacles, &r.Next}
+}
+
+const summaryFocus = 0
+
+// Model is a page for periodically reviewing progress towards each goal
+type Model struct {
+	common common.Common
+	whys   []data.Why
+
+	period data.Period
+	start  time.Time
+	end    time.Time
+	data   common.ReviewMsg
+
+	sections     []reviewSection
+	sectionIndex int
+	// focusIndex is summaryFocus, or one more than the index of the focused
+	// question
+	focusIndex int
+	message    string
+
+	height int
+	width  int
+
+	keys keyMap
+	help help.Model
+}
+
+type reviewSection struct {
+	why     *data.Why
+	summary data.Summary
+	review  data.Review
+	inputs  []textinput.Model
+
+	// subReviews are the goal's reviews for the sub-periods rolled up by
+	// this review
+	subReviews []data.Review
+}
+
+func New(c common.Common) *Model {
+	m := &Model{
+		common: c,
+		keys:   defaultKeys,
+		help:   help.New(),
+	}
+	c.Keys.Register("review", &m.keys)
+	m.start, m.end = m.period.Bounds(c.CurrentDay())
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.common.GetReviewData(m.period, m.start, m.end)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.SetSize(msg.Height, msg.Width)
+	case common.WhyDataMsg:
+		if msg.Status != data.Active {
+			break
+		}
+		if msg.Data != nil {
+			m.whys = msg.Data
+			cmds = append(cmds, m.makeSections())
+		}
+	case common.ReviewMsg:
+		if msg.Error != nil {
+			m.message = msg.Error.Error()
+			break
+		}
+		if msg.Period != m.period || !msg.Start.Equal(m.start) {
+			// a response for a period we've since navigated away from
+			break
+		}
+		m.data = msg
+		cmds = append(cmds, m.makeSections())
+	case common.ReviewsSavedMsg:
+		if msg.Error != nil {
+			m.message = msg.Error.Error()
+		} else {
+			m.message = "review saved"
+			cmds = append(cmds, m.common.GetReviewData(m.period, m.start, m.end))
+		}
+	case common.ErrMsg:
+		if msg.Error != nil {
+			m.message = msg.Error.Error()
+		}
+	case common.UndoMsg:
+		m.message = msg.String()
+		if msg.Error == nil && !m.Typing() {
+			// reading the reviews again would lose the answers being typed
+			cmds = append(cmds, m.common.GetReviewData(m.period, m.start, m.end))
+		}
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		if len(m.sections) == 0 {
+			if key.Matches(msg, m.keys.Quit) {
+				return m, tea.Quit
+			}
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keys.Submit):
+			return m, m.submit()
+		case key.Matches(msg, m.keys.ChangeFocus, m.keys.ChangeFocusBack):
+			if key.Matches(msg, m.keys.ChangeFocus) {
+				m.focusIndex++
+			} else {
+				m.focusIndex--
+			}
+			if m.focusIndex < summaryFocus {
+				m.focusIndex = len(questions)
+			}
+			if m.focusIndex > len(questions) {
+				m.focusIndex = summaryFocus
+			}
+			return m, m.focusInput()
+		case key.Matches(msg, m.keys.Escape):
+			m.focusIndex = summaryFocus
+			return m, m.focusInput()
+		}
+
+		if m.focusIndex != summaryFocus {
+			if msg.Type == tea.KeyEnter {
+				m.focusIndex++
+				if m.focusIndex > len(questions) {
+					m.focusIndex = summaryFocus
+				}
+				return m, m.focusInput()
+			}
+			break
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Help):
+			m.help.ShowAll = !m.help.ShowAll
+		case key.Matches(msg, m.keys.Right):
+			m.sectionIndex++
+		case key.Matches(msg, m.keys.Left):
+			m.sectionIndex--
+		case key.Matches(msg, m.keys.Answer):
+			m.focusIndex = 1
+			return m, m.focusInput()
+		case key.Matches(msg, m.keys.PrevPeriod, m.keys.NextPeriod, m.keys.CyclePeriod):
+			switch {
+			case key.Matches(msg, m.keys.PrevPeriod):
+				m.start, m.end = m.period.Prev(m.start)
+			case key.Matches(msg, m.keys.NextPeriod):
+				m.start, m.end = m.period.Next(m.end)
+			case key.Matches(msg, m.keys.CyclePeriod):
+				m.period = (m.period + 1) % (data.Yearly + 1)
+				m.start, m.end = m.period.Bounds(m.common.CurrentDay())
+			}
+			m.message = ""
+			m.sections = nil
+			return m, m.common.GetReviewData(m.period, m.start, m.end)
+		}
+	}
+
+	if m.sectionIndex < 0 {
+		m.sectionIndex = len(m.sections) - 1
+	}
+	if m.sectionIndex > len(m.sections)-1 {
+		m.sectionIndex = 0
+	}
+
+	if len(m.sections) > 0 {
+		// only the focused section's inputs can be focused
+		inputs := m.sections[m.sectionIndex].inputs
+		for i := range inputs {
+			inputs[i], cmd = inputs[i].Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// Typing reports whether an answer is being typed
+func (m *Model) Typing() bool {
+	return m.focusIndex != summaryFocus
+}
+
+// focusInput focuses the input for the question at focusIndex, if any, and
+// blurs all the others
+func (m *Model) focusInput() tea.Cmd {
+	var cmd tea.Cmd
+	inputs := m.sections[m.sectionIndex].inputs
+	for i := range inputs {
+		if i == m.focusIndex-1 {
+			cmd = inputs[i].Focus()
+		} else {
+			inputs[i].Blur()
+		}
+	}
+	return cmd
+}
+
+func (m *Model) submit() tea.Cmd {
+	var reviews []data.Review
+	for _, section := range m.sections {
+		review := section.review
+		review.Period = m.period
+		review.Start = m.start
+		review.End = m.end
+		review.WhyID = 0
+		if section.why != nil {
+			review.WhyID = section.why.ID
+		}
+		for i, answer := range answers(&review) {
+			*answer = section.inputs[i].Value()
+		}
+		reviews = append(reviews, review)
+	}
+	m.focusIndex = summaryFocus
+	m.message = "saving..."
+	return tea.Batch(m.focusInput(), m.common.UpsertReviews(reviews))
+}
+
+// makeSections lays out a section for every top-level goal, followed by one
+// for intentions without a goal, from the most recently loaded review data.
+// Answers which have been typed but not saved are kept, along with the focus,
+// returning the command which focuses the input being typed into.
+func (m *Model) makeSections() tea.Cmd {
+	if m.data.Start.IsZero() {
+		return nil
+	}
+	// sections are cleared when moving to another period, so any left are
+	// for this one
+	unsaved := make(map[uint]map[int]string)
+	for _, section := range m.sections {
+		var id uint
+		if section.why != nil {
+			id = section.why.ID
+		}
+		unsaved[id] = make(map[int]string)
+		for i, answer := range answers(&section.review) {
+			if value := section.inputs[i].Value(); value != *answer {
+				unsaved[id][i] = value
+			}
+		}
+	}
+
+	// intentions whose goals have all been archived are reviewed with those
+	// that have none, as they are when giving outcomes
+	active := make(map[uint]bool)
+	for _, why := range m.whys {
+		active[why.ID] = true
+	}
+	var intentions []data.Intention
+	for _, intention := range m.data.Intentions {
+		var linked []*data.Why
+		for _, why := range intention.Whys {
+			if active[why.ID] {
+				linked = append(linked, why)
+			}
+		}
+		intention.Whys = linked
+		intentions = append(intentions, intention)
+	}
+	summaries := data.Summarize(intentions, m.data.Days)
+	reviews := make(map[uint]data.Review)
+	for _, review := range m.data.Reviews {
+		reviews[review.WhyID] = review
+	}
+	subReviews := make(map[uint][]data.Review)
+	for _, review := range m.data.SubReviews {
+		subReviews[review.WhyID] = append(subReviews[review.WhyID], review)
+	}
+
+	var sections []reviewSection
+	// sub-goals are reviewed as part of their top-level goals
+	roots := data.Roots(m.whys)
+	for i := range m.whys {
+		id := m.whys[i].ID
+		if roots[id] != id {
+			continue
+		}
+		section := m.newSection(&m.whys[i], summaries[id], reviews[id])
+		section.subReviews = subReviews[id]
+		sections = append(sections, section)
+	}
+	section := m.newSection(nil, summaries[0], reviews[0])
+	section.subReviews = subReviews[0]
+	sections = append(sections, section)
+
+	for _, section := range sections {
+		var id uint
+		if section.why != nil {
+			id = section.why.ID
+		}
+		for i, value := range unsaved[id] {
+			section.inputs[i].SetValue(value)
+		}
+	}
+
+	m.sections = sections
+	if m.sectionIndex > len(m.sections)-1 {
+		m.sectionIndex = 0
+		m.focusIndex = summaryFocus
+	}
+	return m.focusInput()
+}
+
+func (m *Model) newSection(why *data.Why, summary *data.Summary, review data.Review) reviewSection {
+	section := reviewSection{why: why, review: review}
+	if summary != nil {
+		section.summary = *summary
+	}
+	for _, answer := range answers(&section.review) {
+		input := textinput.New()
+		input.Width = m.common.Config.Appearance.Width - 6
+		input.Prompt = "> "
+		input.Placeholder = "say more..."
+		input.SetValue(*answer)
+		section.inputs = append(section.inputs, input)
+	}
+	return section
+}
+
+func (m *Model) View() string {
+	var s []string
+
+	s = append(s, promptStyle.Render(periodTitle(m.period, m.start, m.end)), "")
+
+	if len(m.sections) == 0 {
+		s = append(s, dimStyle.Render("loading..."))
+	} else {
+		s = append(s, m.overview(), "")
+		s = append(s, m.sectionView(m.sections[m.sectionIndex]))
+		s = append(s, fmt.Sprintf("Page %d/%d to review", m.sectionIndex+1, len(m.sections)))
+	}
+	if m.message != "" {
+		s = append(s, m.message)
+	}
+	s = append(s, "", m.help.View(m.keys))
+
+	final := lipgloss.JoinVertical(lipgloss.Center, s...)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, docStyle.Render(final))
+}
+
+// overview renders a line per goal comparing completion and effort
+func (m *Model) overview() string {
+	var lines []string
+	for i, section := range m.sections {
+		prefix, name, color := sectionTitle(section)
+		line := fmt.Sprintf("%-4s%-23s %3.0f%% done  %3d pomos",
+			prefix, truncate(name, 23), section.summary.CompletionRate()*100, section.summary.Pomos)
+		style := lipgloss.NewStyle().Foreground(color)
+		if i == m.sectionIndex {
+			line = "• " + style.Inherit(selectedStyle).Render(line)
+		} else {
+			line = "  " + style.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m *Model) sectionView(section reviewSection) string {
+	var s []string
+	prefix, name, color := sectionTitle(section)
+	s = append(s, titleStyle(color).Render(prefix+" "+name))
+
+	sum := section.summary
+	s = append(s, fmt.Sprintf("%d/%d intentions done (%.0f%%), %d cancelled, %d unintended",
+		sum.Done, sum.Intentions-sum.Cancelled, sum.CompletionRate()*100, sum.Cancelled, sum.Unintended))
+	if section.why != nil {
+		s = append(s, fmt.Sprintf("%d pomos, enough on %d/%d reviewed days", sum.Pomos, sum.Enough, sum.Reviewed))
+	} else {
+		s = append(s, fmt.Sprintf("%d pomos", sum.Pomos))
+	}
+
+	s = append(s, "")
+	if sub, ok := m.period.Sub(); ok {
+		// longer periods have too many days to list every reflection, so we
+		// show the reviews of the periods they are made up of instead
+		if len(section.subReviews) == 0 {
+			s = append(s, dimStyle.Render("no "+sub.String()+" reviews in this period"))
+		}
+		for _, review := range section.subReviews {
+			s = append(s, promptStyle.Render(periodTitle(sub, review.Start, review.End)))
+			for i, answer := range answers(&review) {
+				if *answer != "" {
+					s = append(s, reflectionStyle.Copy().Width(m.common.Config.Appearance.Width-4).Render(questions[i]+" "+*answer))
+				}
+			}
+		}
+	} else {
+		if len(sum.Reflections) == 0 {
+			s = append(s, dimStyle.Render("no reflections this week"))
+		}
+		for _, day := range sum.Reflections {
+			s = append(s, reflectionStyle.Copy().Width(m.common.Config.Appearance.Width-4).Render(day.Date.Format("Mon")+": "+day.Reflection))
+		}
+	}
+
+	for i, question := range questions {
+		s = append(s, "")
+		if m.focusIndex == i+1 {
+			question = selectedStyle.Render(question)
+		}
+		s = append(s, question, section.inputs[i].View())
+	}
+
+	return sectionStyle(color, m.common.Config.Appearance.Width).Render(lipgloss.JoinVertical(lipgloss.Left, s...))
+}
+
+func periodTitle(period data.Period, start, end time.Time) string {
+	switch period {
+	case data.Monthly:
+		return "Monthly review: " + start.Format("January 2006")
+	case data.Quarterly:
+		return fmt.Sprintf("Quarterly review: Q%d %d", (int(start.Month())-1)/3+1, start.Year())
+	case data.Yearly:
+		return "Yearly review: " + start.Format("2006")
+	}
+	return fmt.Sprintf("Weekly review: %s to %s",
+		start.Format("Mon Jan 2"), end.Format("Mon Jan 2, 2006"))
+}
+
+func sectionTitle(section reviewSection) (prefix, name string, color lipgloss.Color) {
+	if section.why == nil {
+		return "&", "MISC", miscColor
+	}
+	return section.why.Code, section.why.Name, section.why.Color
+}
+
+func truncate(s string, n int) string {
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+func (m *Model) SetSize(height, width int) {
+	m.height = height
+	m.width = width
+}
+
+type keyMap struct {
+	Left            key.Binding
+	Right           key.Binding
+	PrevPeriod      key.Binding
+	NextPeriod      key.Binding
+	CyclePeriod     key.Binding
+	Answer          key.Binding
+	ChangeFocus     key.Binding
+	ChangeFocusBack key.Binding
+	Escape          key.Binding
+	Submit          key.Binding
+	Help            key.Binding
+	Quit            key.Binding
+}
+
+// defaultKeys are the bindings used unless the user configures others
+var defaultKeys = keyMap{
+	Left: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "prev goal"),
+	),
+	Right: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "next goal"),
+	),
+	PrevPeriod: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "prev period"),
+	),
+	NextPeriod: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next period"),
+	),
+	CyclePeriod: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "change period"),
+	),
+	Answer: key.NewBinding(
+		key.WithKeys("enter", "a"),
+		key.WithHelp("enter/a", "answer"),
+	),
+	ChangeFocus: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "+focus"),
+	),
+	ChangeFocusBack: key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("shift+tab", "-focus"),
+	),
+	Escape: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "unfocus"),
+	),
+	Submit: key.NewBinding(
+		key.WithKeys("ctrl+d"),
+		key.WithHelp("ctrl+d", "save review"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "ctrl+c"),
+		key.WithHelp("q", "quit"),
+	),
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Answer, k.Submit, k.Help, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Left, k.Right, k.PrevPeriod, k.NextPeriod, k.CyclePeriod}, // first column
+		{k.Answer, k.ChangeFocus, k.ChangeFocusBack, k.Escape},
+		{k.Submit, k.Help, k.Quit},
+	}
+}
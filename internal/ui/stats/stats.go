@@ -0,0 +1,436 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benhsm/goalie/internal/data"
+	"github.com/benhsm/goalie/internal/ui/common"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	docStyle    = lipgloss.NewStyle().Margin(1, 2)
+	promptStyle = lipgloss.NewStyle().Bold(true)
+	dimStyle    = lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#969B86", Dark: "#696969"})
+	selectedStyle = lipgloss.NewStyle().Bold(true)
+	detailStyle   = func(color lipgloss.Color, width int) lipgloss.Style {
+		return lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder(), true).
+			BorderForeground(color).
+			Width(width).
+			Padding(0, 1)
+	}
+	miscColor = lipgloss.Color("#808080")
+)
+
+// windows are the numbers of days, ending with the current day, over which
+// statistics can be computed
+var windows = []int{7, 30, 90, 365}
+
+// sparks are the characters of a sparkline, from lowest to highest
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Model is a page showing how each goal has fared over a window of recent
+// days: how much was intended, done and cancelled, the effort spent on it,
+// and how often the day's outcome was judged enough, with trends over the
+// window.
+type Model struct {
+	common common.Common
+	whys   []data.Why
+
+	window int
+	start  time.Time
+	end    time.Time
+	data   common.StatsMsg
+
+	rows       []row
+	focusIndex int
+	message    string
+	// undoMessage reports the last change undone or redone, until a key is
+	// pressed
+	undoMessage string
+
+	height int
+	width  int
+
+	keys keyMap
+	help help.Model
+}
+
+// row holds the statistics of one goal, or of intentions without a goal if
+// why is nil
+type row struct {
+	why     *data.Why
+	summary data.Summary
+	// done and pomos hold the intentions done and pomodoros spent in each
+	// bucket of the window, oldest first
+	done  []int
+	pomos []int
+}
+
+func New(c common.Common) *Model {
+	m := &Model{
+		common: c,
+		window: 1,
+		keys:   defaultKeys,
+		help:   help.New(),
+	}
+	c.Keys.Register("stats", &m.keys)
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.fetch()
+}
+
+// fetch requests the data for the selected window, which ends on the current
+// day
+func (m *Model) fetch() tea.Cmd {
+	m.end = m.common.CurrentDay()
+	m.start = m.end.AddDate(0, 0, -(windows[m.window] - 1))
+	return m.common.GetStats(m.start, m.end)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.SetSize(msg.Height, msg.Width)
+	case common.WhyDataMsg:
+		if msg.Status != data.Active {
+			break
+		}
+		if msg.Data != nil {
+			m.whys = msg.Data
+			m.makeRows()
+		}
+	case common.StatsMsg:
+		if msg.Error != nil {
+			m.message = msg.Error.Error()
+			break
+		}
+		if !msg.Start.Equal(m.start) || !msg.End.Equal(m.end) {
+			// a response for a window we've since changed
+			break
+		}
+		m.message = ""
+		m.data = msg
+		m.makeRows()
+	case common.UndoMsg:
+		m.undoMessage = msg.String()
+		if msg.Error == nil {
+			cmds = append(cmds, m.fetch())
+		}
+	case tea.KeyMsg:
+		m.undoMessage = ""
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Help):
+			m.help.ShowAll = !m.help.ShowAll
+		case key.Matches(msg, m.keys.Up):
+			m.focusIndex--
+		case key.Matches(msg, m.keys.Down):
+			m.focusIndex++
+		case key.Matches(msg, m.keys.Shorter):
+			if m.window > 0 {
+				m.window--
+				cmds = append(cmds, m.fetch())
+			}
+		case key.Matches(msg, m.keys.Longer):
+			if m.window < len(windows)-1 {
+				m.window++
+				cmds = append(cmds, m.fetch())
+			}
+		}
+	}
+
+	if m.focusIndex < 0 {
+		m.focusIndex = len(m.rows) - 1
+	}
+	if m.focusIndex > len(m.rows)-1 {
+		m.focusIndex = 0
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// bucketDays returns the number of days summed into each point of a trend,
+// so that trends over long windows still fit on a line
+func (m *Model) bucketDays() int {
+	switch {
+	case windows[m.window] > 90:
+		return 14
+	case windows[m.window] > 30:
+		return 7
+	}
+	return 1
+}
+
+// makeRows computes the statistics of each top-level goal, and of intentions
+// without a goal, from the data of the current window
+func (m *Model) makeRows() {
+	if m.data.Start.IsZero() {
+		return
+	}
+	size := m.bucketDays()
+	n := (windows[m.window] + size - 1) / size
+	buckets := make([][]data.Intention, n)
+	for _, intention := range m.data.Intentions {
+		// the last bucket ends on the current day, so the first may be short
+		i := n - 1 - int(m.end.Sub(intention.Date).Hours()/24+0.5)/size
+		if i >= 0 && i < n {
+			buckets[i] = append(buckets[i], intention)
+		}
+	}
+	trends := make([]map[uint]*data.Summary, n)
+	for i := range buckets {
+		trends[i] = data.Summarize(buckets[i], nil)
+	}
+
+	summaries := data.Summarize(m.data.Intentions, m.data.Days)
+	newRow := func(why *data.Why, id uint) row {
+		r := row{why: why, done: make([]int, n), pomos: make([]int, n)}
+		if s := summaries[id]; s != nil {
+			r.summary = *s
+		}
+		for i := range trends {
+			if s := trends[i][id]; s != nil {
+				r.done[i] = s.Done
+				r.pomos[i] = s.Pomos
+			}
+		}
+		return r
+	}
+
+	m.rows = nil
+	shown := make(map[uint]bool)
+	// sub-goals are counted towards their top-level goals
+	roots := data.Roots(m.whys)
+	for i := range m.whys {
+		if roots[m.whys[i].ID] != m.whys[i].ID {
+			continue
+		}
+		m.rows = append(m.rows, newRow(&m.whys[i], m.whys[i].ID))
+		shown[m.whys[i].ID] = true
+	}
+	// archived goals are only shown if they have history in the window
+	var others []*data.Why
+	for _, intention := range m.data.Intentions {
+		others = append(others, intention.Whys...)
+	}
+	for i := range m.data.Days {
+		if m.data.Days[i].WhyID != 0 {
+			others = append(others, &m.data.Days[i].Why)
+		}
+	}
+	for _, why := range others {
+		if !shown[why.ID] {
+			m.rows = append(m.rows, newRow(why, why.ID))
+			shown[why.ID] = true
+		}
+	}
+	m.rows = append(m.rows, newRow(nil, 0))
+	if m.focusIndex > len(m.rows)-1 {
+		m.focusIndex = 0
+	}
+}
+
+func (m *Model) View() string {
+	var s []string
+
+	title := fmt.Sprintf("The last %d days, %s to %s", windows[m.window],
+		m.start.Format("Jan 2"), m.end.Format("Jan 2, 2006"))
+	s = append(s, promptStyle.Render(title), "")
+
+	if len(m.rows) == 0 {
+		s = append(s, dimStyle.Render("loading..."))
+	} else {
+		s = append(s, m.table(), "")
+		s = append(s, m.detail(m.rows[m.focusIndex]))
+	}
+	if m.message != "" {
+		s = append(s, m.message)
+	}
+	if m.undoMessage != "" {
+		s = append(s, m.undoMessage)
+	}
+	s = append(s, "", m.help.View(m.keys))
+
+	final := lipgloss.JoinVertical(lipgloss.Center, s...)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, docStyle.Render(final))
+}
+
+// table renders a line of figures per goal
+func (m *Model) table() string {
+	lines := []string{dimStyle.Render(fmt.Sprintf("  %-19s %5s %5s %5s %5s %6s",
+		"goal", "int", "done", "cxl", "pomos", "enough"))}
+	for i, r := range m.rows {
+		prefix, name, color := rowTitle(r)
+		line := fmt.Sprintf("%-4s%-15s %5d %5s %5s %5d %6s",
+			prefix, truncate(name, 15), r.summary.Intentions,
+			percent(r.summary.Done, r.summary.Intentions-r.summary.Cancelled),
+			percent(r.summary.Cancelled, r.summary.Intentions),
+			r.summary.Pomos,
+			percent(r.summary.Enough, r.summary.Reviewed))
+		style := lipgloss.NewStyle().Foreground(color)
+		if i == m.focusIndex {
+			line = "• " + style.Inherit(selectedStyle).Render(line)
+		} else {
+			line = "  " + style.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// detail renders bars for the rates of the focused goal, and sparklines of
+// what was done and the effort spent over the window
+func (m *Model) detail(r row) string {
+	prefix, name, color := rowTitle(r)
+	width := m.common.Config.Appearance.Width
+	style := lipgloss.NewStyle().Foreground(color)
+	barWidth := width - 20
+
+	var s []string
+	title := common.WhyBadgeStyle(color).Render(prefix + " " + name)
+	if r.why != nil && r.why.Archived {
+		title += dimStyle.Render(" archived")
+	}
+	s = append(s, title, "")
+	rates := []struct {
+		label string
+		n, of int
+	}{
+		{"done", r.summary.Done, r.summary.Intentions - r.summary.Cancelled},
+		{"cancelled", r.summary.Cancelled, r.summary.Intentions},
+		{"enough", r.summary.Enough, r.summary.Reviewed},
+	}
+	for _, rate := range rates {
+		s = append(s, fmt.Sprintf("%-10s %s %s", rate.label,
+			style.Render(bar(rate.n, rate.of, barWidth)), percent(rate.n, rate.of)))
+	}
+
+	per := "per day"
+	if size := m.bucketDays(); size > 1 {
+		per = fmt.Sprintf("per %d days", size)
+	}
+	s = append(s, "",
+		fmt.Sprintf("%-10s %s", "done", style.Render(sparkline(r.done))),
+		fmt.Sprintf("%-10s %s", "pomos", style.Render(sparkline(r.pomos))),
+		dimStyle.Render(per+", oldest first"))
+	return detailStyle(color, width).Render(lipgloss.JoinVertical(lipgloss.Left, s...))
+}
+
+// bar renders n out of of as a bar width cells wide
+func bar(n, of, width int) string {
+	filled := 0
+	if of > 0 {
+		filled = (n*width + of/2) / of
+	}
+	return strings.Repeat("█", filled) + dimStyle.Render(strings.Repeat("░", width-filled))
+}
+
+// sparkline renders each value as a bar scaled to the largest of them. Only
+// zero is drawn with the lowest bar.
+func sparkline(values []int) string {
+	highest := 1
+	for _, v := range values {
+		if v > highest {
+			highest = v
+		}
+	}
+	var b strings.Builder
+	for _, v := range values {
+		b.WriteRune(sparks[(v*(len(sparks)-1)+highest-1)/highest])
+	}
+	return b.String()
+}
+
+// percent formats n out of of, or a dash if there is nothing to divide by
+func percent(n, of int) string {
+	if of <= 0 {
+		return "–"
+	}
+	return fmt.Sprintf("%.0f%%", float64(n)/float64(of)*100)
+}
+
+func rowTitle(r row) (prefix, name string, color lipgloss.Color) {
+	if r.why == nil {
+		return "&", "MISC", miscColor
+	}
+	if r.why.Archived {
+		return "-", r.why.Name, r.why.Color
+	}
+	return r.why.Code, r.why.Name, r.why.Color
+}
+
+func truncate(s string, n int) string {
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+func (m *Model) SetSize(height, width int) {
+	m.height = height
+	m.width = width
+}
+
+type keyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Shorter key.Binding
+	Longer  key.Binding
+	Help    key.Binding
+	Quit    key.Binding
+}
+
+// defaultKeys are the bindings used unless the user configures others
+var defaultKeys = keyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "previous goal"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "next goal"),
+	),
+	Shorter: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "shorter window"),
+	),
+	Longer: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→/l", "longer window"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "ctrl+c"),
+		key.WithHelp("q", "quit"),
+	),
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Shorter, k.Longer, k.Help, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Shorter, k.Longer}, // first column
+		{k.Help, k.Quit},                    // second column
+	}
+}
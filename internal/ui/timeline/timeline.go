@@ -0,0 +1,361 @@
+package timeline
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benhsm/goalie/internal/data"
+	"github.com/benhsm/goalie/internal/ui/common"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// span is the number of days fetched from the database at a time
+const span = 7
+
+// dateKey is the layout used to group intentions and reviews by day
+const dateKey = "2006-01-02"
+
+var (
+	docStyle      = lipgloss.NewStyle().Margin(1, 2)
+	dayTitleStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+	dayStyle      = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder(), true).
+			Padding(0, 1).
+			Margin(0, 0, 1, 0)
+	selectedDayStyle = dayStyle.Copy().
+				BorderForeground(lipgloss.Color("#8F26D9"))
+	dimStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#969B86", Dark: "#696969"})
+	checkMark = lipgloss.NewStyle().SetString("✓").
+			Foreground(lipgloss.AdaptiveColor{Light: "#43BF6D", Dark: "#73F59F"}).
+			String()
+	reflectionStyle = lipgloss.NewStyle().Italic(true).Padding(0, 0, 0, 2)
+)
+
+// Model is a page that scrolls backwards through the intentions, outcomes and
+// reflections of prior days
+type Model struct {
+	common common.Common
+
+	// cursor is the most recent day shown at the top of the page
+	cursor     time.Time
+	intentions map[string][]data.Intention
+	days       map[string][]data.Day
+	sessions   map[string][]data.PomoSession
+	errMessage string
+	// undoMessage reports the last change undone or redone, until a key is
+	// pressed
+	undoMessage string
+
+	height int
+	width  int
+
+	keys keyMap
+	help help.Model
+}
+
+func New(c common.Common) *Model {
+	m := &Model{
+		common:     c,
+		cursor:     c.CurrentDay(),
+		intentions: make(map[string][]data.Intention),
+		days:       make(map[string][]data.Day),
+		sessions:   make(map[string][]data.PomoSession),
+		keys:       defaultKeys,
+		help:       help.New(),
+	}
+	c.Keys.Register("timeline", &m.keys)
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.fetch()
+}
+
+// fetch requests the window of days ending at the cursor
+func (m *Model) fetch() tea.Cmd {
+	return m.common.GetTimeline(m.cursor.AddDate(0, 0, -(span-1)), m.cursor)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.SetSize(msg.Height, msg.Width)
+	case common.TimelineMsg:
+		if msg.Error != nil {
+			m.errMessage = msg.Error.Error()
+			break
+		}
+		m.errMessage = ""
+		for d := msg.Start; !d.After(msg.End); d = d.AddDate(0, 0, 1) {
+			delete(m.intentions, d.Format(dateKey))
+			delete(m.days, d.Format(dateKey))
+			delete(m.sessions, d.Format(dateKey))
+		}
+		for _, intention := range msg.Intentions {
+			k := intention.Date.Format(dateKey)
+			m.intentions[k] = append(m.intentions[k], intention)
+		}
+		for _, day := range msg.Days {
+			k := day.Date.Format(dateKey)
+			m.days[k] = append(m.days[k], day)
+		}
+		for _, session := range msg.Sessions {
+			k := session.Intention.Date.Format(dateKey)
+			m.sessions[k] = append(m.sessions[k], session)
+		}
+	case common.UndoMsg:
+		m.undoMessage = msg.String()
+		if msg.Error == nil {
+			cmds = append(cmds, m.fetch())
+		}
+	case tea.KeyMsg:
+		m.undoMessage = ""
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Help):
+			m.help.ShowAll = !m.help.ShowAll
+		case key.Matches(msg, m.keys.Older):
+			m.cursor = m.cursor.AddDate(0, 0, -1)
+			cmds = append(cmds, m.fetch())
+		case key.Matches(msg, m.keys.Newer):
+			if m.cursor.Before(m.common.CurrentDay()) {
+				m.cursor = m.cursor.AddDate(0, 0, 1)
+				cmds = append(cmds, m.fetch())
+			}
+		case key.Matches(msg, m.keys.OlderWeek):
+			m.cursor = m.cursor.AddDate(0, 0, -span)
+			cmds = append(cmds, m.fetch())
+		case key.Matches(msg, m.keys.NewerWeek):
+			m.cursor = m.cursor.AddDate(0, 0, span)
+			if today := m.common.CurrentDay(); m.cursor.After(today) {
+				m.cursor = today
+			}
+			cmds = append(cmds, m.fetch())
+		case key.Matches(msg, m.keys.Today):
+			m.cursor = m.common.CurrentDay()
+			cmds = append(cmds, m.fetch())
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m *Model) View() string {
+	var blocks []string
+	// leave room for the help view
+	remaining := m.height - 4
+	for i := 0; i < span; i++ {
+		day := m.cursor.AddDate(0, 0, -i)
+		block := m.renderDay(day, i == 0)
+		h := lipgloss.Height(block)
+		if i > 0 && h > remaining {
+			break
+		}
+		remaining -= h
+		blocks = append(blocks, block)
+	}
+	if m.errMessage != "" {
+		blocks = append(blocks, m.errMessage)
+	}
+	if m.undoMessage != "" {
+		blocks = append(blocks, m.undoMessage)
+	}
+	blocks = append(blocks, m.help.View(m.keys))
+	final := lipgloss.JoinVertical(lipgloss.Center, blocks...)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Top, docStyle.Render(final))
+}
+
+func (m *Model) renderDay(day time.Time, selected bool) string {
+	var s []string
+	year, month, date := day.Date()
+	title := fmt.Sprintf("%s %d, %s %d", day.Weekday().String(), date, month.String(), year)
+
+	intentions := m.intentions[day.Format(dateKey)]
+	reviews := m.days[day.Format(dateKey)]
+	sessions := m.sessions[day.Format(dateKey)]
+
+	var total, done, pomoCount int
+	for _, intention := range intentions {
+		pomoCount += intention.Pomos
+		if !intention.Cancelled {
+			total++
+			if intention.Done {
+				done++
+			}
+		}
+	}
+	if len(intentions) > 0 {
+		title += dimStyle.Render(fmt.Sprintf("  %d/%d done, %d pomos", done, total, pomoCount))
+	}
+	s = append(s, dayTitleStyle.Render(title))
+
+	if len(intentions) == 0 && len(reviews) == 0 {
+		s = append(s, dimStyle.Render("nothing recorded"))
+	}
+	for _, intention := range intentions {
+		s = append(s, renderIntention(intention))
+	}
+	if len(sessions) > 0 {
+		s = append(s, "")
+	}
+	for _, session := range sessions {
+		s = append(s, renderSession(session, m.common.Config.Day.Location))
+	}
+	if len(reviews) > 0 {
+		s = append(s, "")
+	}
+	for _, review := range reviews {
+		s = append(s, m.renderReview(review)...)
+	}
+
+	style := dayStyle
+	if selected {
+		style = selectedDayStyle
+	}
+	return style.Copy().Width(m.common.Config.Appearance.Width).Render(lipgloss.JoinVertical(lipgloss.Left, s...))
+}
+
+func renderIntention(i data.Intention) string {
+	var color lipgloss.TerminalColor = lipgloss.NoColor{}
+	if len(i.Whys) > 0 {
+		color = i.Whys[0].Color
+	}
+	style := lipgloss.NewStyle().Foreground(color)
+
+	var prefix string
+	switch {
+	case i.Cancelled:
+		prefix = dimStyle.Render("[x] ")
+		style = dimStyle.Copy().Strikethrough(true)
+	case i.Done:
+		prefix = "[" + checkMark + "] "
+		style = style.Strikethrough(true)
+	default:
+		prefix = "[ ] "
+	}
+	if i.Unintended {
+		prefix = "+" + prefix
+	} else {
+		prefix = " " + prefix
+	}
+	content := i.Content
+	if i.Pomos > 0 {
+		content += " " + strings.Repeat("🍅", i.Pomos)
+	}
+	var postponed string
+	if n := i.Postponed(); n == 1 {
+		postponed = dimStyle.Render(" (postponed 1 day)")
+	} else if n > 1 {
+		postponed = dimStyle.Render(fmt.Sprintf(" (postponed %d days)", n))
+	}
+	return prefix + style.Render(content) + postponed
+}
+
+// renderSession renders a pomodoro session as the time it was worked in loc,
+// the zone days are counted in, so that the day shows when focus actually
+// happened
+func renderSession(p data.PomoSession, loc *time.Location) string {
+	if loc == nil {
+		loc = time.Local
+	}
+	mark := checkMark
+	if !p.Completed {
+		mark = dimStyle.Render("✗")
+	}
+	times := fmt.Sprintf("%s–%s", p.Start.In(loc).Format("15:04"), p.End.In(loc).Format("15:04"))
+	return fmt.Sprintf(" %s %s %s", mark, dimStyle.Render(times), p.Intention.Content)
+}
+
+func (m *Model) renderReview(review data.Day) []string {
+	var badge string
+	if review.WhyID == 0 {
+		badge = common.WhyBadgeStyle(lipgloss.Color("#808080")).Render("& MISC")
+	} else {
+		badge = common.WhyBadgeStyle(review.Why.Color).Render(review.Why.Name)
+	}
+
+	var lines []string
+	if review.WhyID == 0 {
+		lines = append(lines, badge)
+	} else if review.Enough {
+		lines = append(lines, badge+"enough "+checkMark)
+	} else {
+		lines = append(lines, badge+dimStyle.Render("not enough"))
+	}
+	if review.Reflection != "" {
+		lines = append(lines, reflectionStyle.Copy().
+			Width(m.common.Config.Appearance.Width-4).
+			Render(review.Reflection))
+	}
+	return lines
+}
+
+func (m *Model) SetSize(height, width int) {
+	m.height = height
+	m.width = width
+}
+
+type keyMap struct {
+	Older     key.Binding
+	Newer     key.Binding
+	OlderWeek key.Binding
+	NewerWeek key.Binding
+	Today     key.Binding
+	Help      key.Binding
+	Quit      key.Binding
+}
+
+// defaultKeys are the bindings used unless the user configures others
+var defaultKeys = keyMap{
+	Older: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "older"),
+	),
+	Newer: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "newer"),
+	),
+	OlderWeek: key.NewBinding(
+		key.WithKeys("pgdown", "J"),
+		key.WithHelp("pgdn/J", "week back"),
+	),
+	NewerWeek: key.NewBinding(
+		key.WithKeys("pgup", "K"),
+		key.WithHelp("pgup/K", "week forward"),
+	),
+	Today: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "today"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "ctrl+c"),
+		key.WithHelp("q", "quit"),
+	),
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Older, k.Newer, k.Help, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Older, k.Newer, k.OlderWeek, k.NewerWeek}, // first column
+		{k.Today, k.Help, k.Quit},                    // second column
+	}
+}
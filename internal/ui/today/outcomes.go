@@ -2,7 +2,6 @@ package today
 
 import (
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/benhsm/goalie/internal/data"
@@ -55,14 +54,14 @@ const (
 	reflectFocus
 )
 
-func newOutcomeModel(c common.Common, whys []data.Why, intentions []data.Intention) outcomeModel {
+func newOutcomeModel(c common.Common, keys outcomesKeyMap, whys []data.Why, intentions []data.Intention) outcomeModel {
 	return outcomeModel{
 		Common:     c,
 		whys:       whys,
 		intentions: intentions,
-		sections:   makeOutcomeSections(whys, intentions),
+		sections:   makeOutcomeSections(whys, intentions, c.Config.Appearance.Width),
 		help:       help.New(),
-		keys:       outcomeKeys,
+		keys:       keys,
 	}
 }
 
@@ -155,12 +154,12 @@ func (m outcomeModel) Update(msg tea.Msg) (outcomeModel, tea.Cmd) {
 
 					if m.sections[m.sectionIndex].why != nil {
 						why = m.sections[m.sectionIndex].why
-						content = fmt.Sprintf("%d) %s", why.Number, m.sections[m.sectionIndex].addInput.Value())
+						content = data.Prefix([]*data.Why{why}) + " " + m.sections[m.sectionIndex].addInput.Value()
 						newIntention = data.Intention{
 							Whys: []*data.Why{why},
 						}
 					} else {
-						content = fmt.Sprintf("&) %s", m.sections[m.sectionIndex].addInput.Value())
+						content = data.NoGoalPrefix + " " + m.sections[m.sectionIndex].addInput.Value()
 						newIntention = data.Intention{}
 					}
 					newIntention.Content = content
@@ -195,7 +194,7 @@ func (m outcomeModel) Update(msg tea.Msg) (outcomeModel, tea.Cmd) {
 					m.sectionIndex--
 				case key.Matches(msg, m.keys.Yes):
 					m.sections[m.sectionIndex].enough = true
-				case key.Matches(msg, m.keys.Yes):
+				case key.Matches(msg, m.keys.No):
 					m.sections[m.sectionIndex].enough = false
 				case key.Matches(msg, m.keys.Add):
 					cmd := m.sections[m.sectionIndex].addInput.Focus()
@@ -248,7 +247,7 @@ func (m outcomeModel) View() string {
 	var name string
 	if m.sections[m.sectionIndex].why != nil {
 		why = m.sections[m.sectionIndex].why
-		prefix = strconv.Itoa(why.Number)
+		prefix = why.Code
 		color = why.Color
 		name = why.Name
 	} else {
@@ -258,6 +257,7 @@ func (m outcomeModel) View() string {
 	}
 
 	title := titleStyle(color).Render(prefix + " " + name)
+	width := m.Config.Appearance.Width
 	var s []string
 	for i, intention := range m.sections[m.sectionIndex].intentions {
 		var renderedIntention string
@@ -268,11 +268,11 @@ func (m outcomeModel) View() string {
 			selected = false
 		}
 		if intention.Cancelled {
-			renderedIntention = cancelledRender(intention, selected)
+			renderedIntention = cancelledRender(intention, selected, width-6)
 		} else if intention.Done {
-			renderedIntention = doneItemRender(intention, selected)
+			renderedIntention = doneItemRender(intention, selected, width-6)
 		} else {
-			renderedIntention = listItemRender(intention, selected)
+			renderedIntention = listItemRender(intention, selected, width-6)
 		}
 		s = append(s, renderedIntention)
 	}
@@ -280,7 +280,7 @@ func (m outcomeModel) View() string {
 	inputBox := lipgloss.NewStyle().
 		BorderForeground(color).
 		Border(lipgloss.RoundedBorder(), true).
-		Width(50).
+		Width(width).
 		Padding(0, 0, 0, 1).
 		Render(m.sections[m.sectionIndex].input.View())
 
@@ -298,54 +298,83 @@ func (m outcomeModel) View() string {
 	outcomeBox := lipgloss.JoinVertical(lipgloss.Left, s...)
 	outcomeBox = lipgloss.NewStyle().Border(lipgloss.NormalBorder(), true).
 		BorderForeground(color).
-		Width(50).
+		Width(width).
 		Render(outcomeBox)
 	rightBox := lipgloss.JoinVertical(lipgloss.Left, title, outcomeBox, enoughLine, inputBox)
 	goalCount := fmt.Sprintf("Page %d/%d to review", m.sectionIndex+1, len(m.sections))
 	rightBox = lipgloss.JoinVertical(lipgloss.Right, prompt, "", rightBox, goalCount)
-	rightBox = lipgloss.JoinVertical(lipgloss.Center, rightBox, "", m.help.View(outcomeKeys))
+	rightBox = lipgloss.JoinVertical(lipgloss.Center, rightBox, "", m.help.View(m.keys))
 
 	return sectionStyle.Render(rightBox)
 }
 
-func makeOutcomeSections(whys []data.Why, intentions []data.Intention) []outcomeSection {
+// fillReviews fills in the answers of a day which has been reviewed before,
+// so that they are kept unless changed
+func (m *outcomeModel) fillReviews(days []data.Day) {
+	for _, day := range days {
+		for i := range m.sections {
+			why := m.sections[i].why
+			if (why == nil && day.WhyID == 0) || (why != nil && why.ID == day.WhyID) {
+				m.sections[i].enough = day.Enough
+				m.sections[i].input.SetValue(day.Reflection)
+			}
+		}
+	}
+}
+
+// makeOutcomeSections lays out a section for each top-level goal in whys,
+// with the intentions of the goal and its sub-goals, followed by one for
+// intentions without an active goal
+func makeOutcomeSections(whys []data.Why, intentions []data.Intention, width int) []outcomeSection {
 	result := []outcomeSection{}
+	roots := data.Roots(whys)
 	for i, why := range whys {
+		if roots[why.ID] != why.ID {
+			continue
+		}
 		section := outcomeSection{}
 		section.why = &whys[i]
 		for _, intention := range intentions {
 			for _, assocWhy := range intention.Whys {
-				if assocWhy.ID == why.ID {
+				if data.RootOf(roots, assocWhy.ID) == why.ID {
 					section.intentions = append(section.intentions, intention)
 					break
 				}
 			}
 		}
 		section.addInput = textinput.New()
-		section.addInput.Width = 42
-		section.addInput.Prompt = fmt.Sprintf("  [+] %d) ", why.Number)
+		section.addInput.Width = width - 8
+		section.addInput.Prompt = "  [+] " + data.Prefix([]*data.Why{section.why}) + " "
 		section.addInput.Placeholder = ""
 
 		section.input = textinput.New()
-		section.input.Width = 48
+		section.input.Width = width - 2
 		section.input.Prompt = ""
 		section.input.Placeholder = "say more..."
 		result = append(result, section)
 	}
 
+	// intentions whose goals have all been archived are reviewed with those
+	// that have none
 	miscSection := outcomeSection{}
 	for _, intention := range intentions {
-		if len(intention.Whys) == 0 {
+		active := false
+		for _, assocWhy := range intention.Whys {
+			for _, why := range whys {
+				active = active || assocWhy.ID == why.ID
+			}
+		}
+		if !active {
 			miscSection.intentions = append(miscSection.intentions, intention)
 		}
 	}
 	miscSection.addInput = textinput.New()
-	miscSection.addInput.Width = 42
+	miscSection.addInput.Width = width - 8
 	miscSection.addInput.Prompt = "  [+] &) "
 	miscSection.addInput.Placeholder = ""
 
 	miscSection.input = textinput.New()
-	miscSection.input.Width = 48
+	miscSection.input.Width = width - 2
 	miscSection.input.Prompt = ""
 	miscSection.input.Placeholder = "overall remarks for today"
 	result = append(result, miscSection)
@@ -378,7 +407,8 @@ type outcomesKeyMap struct {
 	Escape          key.Binding
 }
 
-var outcomeKeys = outcomesKeyMap{
+// defaultOutcomeKeys are the bindings used unless the user configures others
+var defaultOutcomeKeys = outcomesKeyMap{
 	Up: key.NewBinding(
 		key.WithKeys("up", "k"),
 		key.WithHelp("↑/k", "move up"),
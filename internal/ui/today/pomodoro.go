@@ -0,0 +1,121 @@
+package today
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/benhsm/goalie/internal/config"
+	"github.com/benhsm/goalie/internal/data"
+	"github.com/benhsm/goalie/internal/ui/common"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	timerStyle = lipgloss.NewStyle().Bold(true).Margin(1, 0, 0, 0)
+	breakStyle = timerStyle.Copy().
+			Foreground(lipgloss.AdaptiveColor{Light: "#43BF6D", Dark: "#73F59F"})
+)
+
+type timerState int
+
+const (
+	timerIdle timerState = iota
+	timerWorking
+	timerBreak
+)
+
+// pomodoroTimer counts down pomodoros worked on an intention and the breaks
+// between them
+type pomodoroTimer struct {
+	config config.PomodoroConfig
+	state  timerState
+
+	intention data.Intention
+	started   time.Time
+	deadline  time.Time
+	now       time.Time
+
+	// completed counts the pomodoros finished since the timer was last idle,
+	// to decide when a long break is due
+	completed int
+	// id distinguishes the ticks of the current countdown from those of
+	// earlier ones which may still be in flight
+	id int
+}
+
+func newPomodoroTimer(c config.PomodoroConfig) pomodoroTimer {
+	return pomodoroTimer{config: c}
+}
+
+// startWork begins a pomodoro on intention
+func (t *pomodoroTimer) startWork(intention data.Intention) tea.Cmd {
+	t.state = timerWorking
+	t.intention = intention
+	cmd := t.countdown(t.config.Work)
+	t.started = t.now
+	return cmd
+}
+
+// session records the pomodoro begun by startWork as ending at end
+func (t pomodoroTimer) session(end time.Time, completed bool) data.PomoSession {
+	return data.PomoSession{
+		IntentionID: t.intention.ID,
+		Start:       t.started,
+		End:         end,
+		Duration:    end.Sub(t.started),
+		Completed:   completed,
+	}
+}
+
+// startBreak begins a break after a pomodoro, a long one if enough pomodoros
+// have been completed in a row
+func (t *pomodoroTimer) startBreak() tea.Cmd {
+	t.state = timerBreak
+	t.completed++
+	if t.completed%t.config.LongBreakEvery == 0 {
+		return t.countdown(t.config.LongBreak)
+	}
+	return t.countdown(t.config.ShortBreak)
+}
+
+func (t *pomodoroTimer) stop() {
+	t.state = timerIdle
+	t.completed = 0
+	t.id++
+}
+
+func (t *pomodoroTimer) countdown(d time.Duration) tea.Cmd {
+	t.id++
+	t.now = time.Now()
+	t.deadline = t.now.Add(d)
+	return t.tick()
+}
+
+func (t pomodoroTimer) tick() tea.Cmd {
+	id := t.id
+	return tea.Tick(time.Second, func(now time.Time) tea.Msg {
+		return common.TimerTickMsg{ID: id, Time: now}
+	})
+}
+
+// expired reports whether the current countdown has run out
+func (t pomodoroTimer) expired() bool {
+	return !t.now.Before(t.deadline)
+}
+
+func (t pomodoroTimer) View() string {
+	remaining := t.deadline.Sub(t.now).Round(time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	clock := fmt.Sprintf("%02d:%02d", int(remaining.Minutes()), int(remaining.Seconds())%60)
+	switch t.state {
+	case timerWorking:
+		return timerStyle.Copy().Foreground(listItemStyle(t.intention)).
+			Render("🍅 " + clock + "  " + t.intention.Content)
+	case timerBreak:
+		return breakStyle.Render("☕ " + clock + "  break")
+	}
+	return ""
+}
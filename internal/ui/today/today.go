@@ -3,7 +3,6 @@ package today
 import (
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -24,11 +23,22 @@ type Model struct {
 	whys       []data.Why
 	intentions []data.Intention
 
-	date         time.Time
+	date time.Time
+	// home is the day the page opened on, before which days are browsed
+	home         time.Time
+	browsing     bool
 	inputPage    inputModel
 	todayPage    todayModel
 	outcomesPage outcomeModel
 	state        activePage
+	keys         keyMaps
+	timer        pomodoroTimer
+	// message reports the last change undone or redone, until a key is
+	// pressed
+	message string
+	// refreshing is true while the day's intentions are read again after a
+	// change was undone or redone, without leaving the input or outcomes
+	refreshing bool
 
 	Err error
 
@@ -36,6 +46,13 @@ type Model struct {
 	width  int
 }
 
+// keyMaps holds the bindings of each of the page's modes
+type keyMaps struct {
+	today    todayKeyMap
+	input    inputKeyMap
+	outcomes outcomesKeyMap
+}
+
 type activePage int
 
 const (
@@ -46,19 +63,31 @@ const (
 )
 
 func New(c common.Common) *Model {
-	return &Model{
+	m := &Model{
 		Common: c,
-		date:   getCurrentDay(),
+		date:   c.CurrentDay(),
+		timer:  newPomodoroTimer(c.Config.Pomodoro),
+		keys: keyMaps{
+			today:    defaultTodayKeys,
+			input:    defaultInputKeys,
+			outcomes: defaultOutcomeKeys,
+		},
 	}
+	c.Keys.Register("today", &m.keys.today)
+	c.Keys.Register("today_input", &m.keys.input)
+	c.Keys.Register("outcomes", &m.keys.outcomes)
+	return m
 }
 
 func (m *Model) Init() tea.Cmd {
 	var cmds []tea.Cmd
-	m.inputPage = newInputModel(m.Common)
-	m.todayPage = newTodayModel(m.Common)
+	m.inputPage = newInputModel(m.Common, m.keys.input)
+	m.todayPage = newTodayModel(m.Common, m.keys.today)
 	m.inputPage.whys = &m.whys
 	m.todayPage.whys = &m.whys
 	m.todayPage.date = &m.date
+	// a day being browsed is shown again read only
+	m.todayPage.setBrowsing(m.browsing, m.browsing)
 	cmds = append(cmds, m.inputPage.Init())
 	cmds = append(cmds, m.GetDaysIntentions(m.date))
 	return tea.Batch(cmds...)
@@ -73,57 +102,195 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.SetSize(msg.Height, msg.Width)
 
 	case common.WhyDataMsg:
+		if msg.Status != data.Active {
+			break
+		}
 		if msg.Data != nil {
 			m.whys = msg.Data
+			if m.state == inputActive {
+				m.inputPage.refill(m.whys)
+			}
+		}
+	case common.TimerTickMsg:
+		if msg.ID != m.timer.id || m.timer.state == timerIdle {
+			return m, nil
+		}
+		m.timer.now = msg.Time
+		if !m.timer.expired() {
+			return m, m.timer.tick()
+		}
+		if m.timer.state == timerBreak {
+			m.timer.stop()
+			return m, nil
+		}
+		session := m.timer.session(m.timer.deadline, true)
+		cmd = m.timer.startBreak()
+		return m, tea.Batch(cmd, m.saveTimer(session, func(i *data.Intention) {
+			i.Pomos++
+		}))
+	case common.PlannedMsg:
+		if m.state != inputActive || !m.inputPage.planning || !msg.Date.Equal(m.inputPage.planDate) {
+			return m, nil
+		}
+		if msg.Error != nil {
+			m.message = msg.Error.Error()
+			return m, nil
+		}
+		m.inputPage.planFor(msg.Intentions, m.whys)
+		return m, nil
+	case common.TimerSavedMsg:
+		if msg.Error != nil {
+			m.message = msg.Error.Error()
+		}
+		return m, nil
+	case common.UndoMsg:
+		m.message = msg.String()
+		if msg.Error != nil || m.state == loading {
+			return m, nil
+		}
+		// whatever was undone may have changed the intentions shown, or
+		// those which outcomes or new intentions are about to be saved with
+		m.refreshing = m.state != todayActive
+		return m, m.GetDaysIntentions(m.date)
+	case common.DayReviewMsg:
+		if msg.Error == nil && m.state == outcomesActive && msg.Date.Equal(m.date) {
+			m.outcomesPage.fillReviews(msg.Days)
 		}
 	case common.IntentionMsg:
-		if len(msg.Yesterday) > 0 {
-			for i := range msg.Yesterday {
-				if !msg.Yesterday[i].Outcome {
+		if m.refreshing {
+			m.refreshing = false
+			return m, m.refresh(msg)
+		}
+		if m.browsing {
+			_, intentions := splitPlanned(msg.Today)
+			m.todayPage.intentions = intentions
+			m.todayPage.planned = 0
+			if m.todayPage.focusIndex >= len(intentions) {
+				m.todayPage.focusIndex = 0
+			}
+			if m.state == outcomesActive {
+				// the day's outcomes have been saved
+				m.todayPage.setBrowsing(true, true)
+			}
+			m.state = todayActive
+			return m, nil
+		}
+		// intentions planned for a day are only its own once confirmed on
+		// its input page
+		_, yesterday := splitPlanned(msg.Yesterday)
+		planned, today := splitPlanned(msg.Today)
+		tomorrow, confirmed := splitPlanned(msg.Tomorrow)
+		m.todayPage.planned = len(tomorrow)
+		if len(yesterday) > 0 {
+			for i := range yesterday {
+				if !yesterday[i].Outcome {
 					m.date = m.date.AddDate(0, 0, -1)
 					m.state = outcomesActive
-					m.outcomesPage = newOutcomeModel(m.Common, m.whys, msg.Yesterday)
+					m.outcomesPage = newOutcomeModel(m.Common, m.keys.outcomes, m.whys, yesterday)
 					m.outcomesPage.date = &m.date
+					m.home = m.date
+					cmds = append(cmds, m.GetDayReviews(m.date))
 					return m, tea.Batch(cmds...)
 				}
 			}
 		}
-		if len(msg.Today) > 0 {
-			if msg.Today[0].Outcome {
+		if len(today) > 0 {
+			if today[0].Outcome && len(confirmed) > 0 {
+				// the next day has begun early
+				m.date = m.date.AddDate(0, 0, 1)
+				m.todayPage.intentions = confirmed
+				m.todayPage.planned = 0
+				m.state = todayActive
+			} else if today[0].Outcome {
 				m.date = m.date.AddDate(0, 0, 1)
 				m.todayPage.intentions = []data.Intention{}
+				m.todayPage.planned = 0
 				m.state = inputActive
+				m.inputPage.offerCarryOver(today)
+				m.inputPage.fill(tomorrow, msg.Recurring, m.whys, m.date)
 			} else {
-				m.todayPage.intentions = msg.Today
+				m.todayPage.intentions = today
 				m.state = todayActive
 			}
 		} else {
 			m.state = inputActive
+			m.inputPage.offerCarryOver(yesterday)
+			m.inputPage.fill(planned, msg.Recurring, m.whys, m.date)
 		}
+		m.home = m.date
 	}
 
 	if msg, ok := msg.(tea.KeyMsg); ok {
 		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
 		}
+		m.message = ""
 	}
 
 	switch m.state {
 	case inputActive:
 		m.inputPage, cmd = m.inputPage.Update(msg)
 		cmds = append(cmds, cmd)
+		if m.inputPage.cancelled {
+			m.state = todayActive
+			break
+		}
+		if m.inputPage.finished && m.inputPage.planning {
+			input := m.inputPage.textInput.Value()
+			parsedIntentions, err := ParseIntentions(m.whys, input)
+			if len(m.inputPage.plannedItems) > 0 && strings.TrimSpace(input) == "" {
+				// removing everything planned for the day is fine
+				err = nil
+			}
+			if err != nil {
+				m.inputPage.finished = false
+				break
+			}
+			for i := range parsedIntentions {
+				// lines left as they were filled in keep the intentions
+				// already planned
+				if planned, ok := m.inputPage.planned[parsedIntentions[i].Content]; ok {
+					parsedIntentions[i] = planned
+				}
+				parsedIntentions[i].Date = m.inputPage.planDate
+				parsedIntentions[i].Position = i
+				parsedIntentions[i].Planned = true
+			}
+			unconfirmed := m.inputPage.unconfirmed(parsedIntentions)
+			cmds = append(cmds, m.SyncIntentions(parsedIntentions, unconfirmed))
+			m.state = loading
+			break
+		}
 		if m.inputPage.finished {
 			input := m.inputPage.textInput.Value()
-			parsedIntentions, err := parseIntentions(m.whys, input)
+			carried := m.inputPage.carried()
+			parsedIntentions, err := ParseIntentions(m.whys, input)
+			if len(carried) > 0 && strings.TrimSpace(input) == "" {
+				// only carrying over intentions is fine
+				err = nil
+			}
 			if err != nil {
 				m.inputPage.finished = false
 			} else {
-				intentions := append(m.todayPage.intentions, parsedIntentions...)
+				for i := range parsedIntentions {
+					// lines left as they were filled in confirm intentions
+					// planned ahead or are instances of recurring intentions
+					content := parsedIntentions[i].Content
+					if planned, ok := m.inputPage.planned[content]; ok {
+						planned.Planned = false
+						parsedIntentions[i] = planned
+					} else if id, ok := m.inputPage.recurring[content]; ok {
+						parsedIntentions[i].RecurringID = &id
+					}
+				}
+				unconfirmed := m.inputPage.unconfirmed(parsedIntentions)
+				intentions := append(m.todayPage.intentions, carried...)
+				intentions = append(intentions, parsedIntentions...)
 				for i := range intentions {
 					intentions[i].Date = m.date
 					intentions[i].Position = i
 				}
-				cmd = m.UpsertIntentions(intentions)
+				cmd = m.SyncIntentions(intentions, unconfirmed)
 				cmds = append(cmds, cmd)
 				m.todayPage.adding = false
 				m.state = loading
@@ -135,17 +302,34 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case todayActive:
 		m.todayPage, cmd = m.todayPage.Update(msg)
 		cmds = append(cmds, cmd)
+		if m.todayPage.toggleTimer {
+			m.todayPage.toggleTimer = false
+			cmds = append(cmds, m.toggleTimer())
+		}
 		if m.todayPage.adding {
-			m.inputPage = newInputModel(m.Common)
+			m.inputPage = newInputModel(m.Common, m.keys.input)
 			m.inputPage.whys = &m.whys
 			m.state = inputActive
 			cmd = m.inputPage.Init()
 			cmds = append(cmds, cmd)
 		}
+		if m.todayPage.planning {
+			m.todayPage.planning = false
+			m.inputPage = newPlanningModel(m.Common, m.keys.input, m.date)
+			m.inputPage.whys = &m.whys
+			m.state = inputActive
+			cmds = append(cmds, m.inputPage.Init())
+		}
+		if !m.todayPage.goTo.IsZero() {
+			cmds = append(cmds, m.goToDay(m.todayPage.goTo))
+			m.todayPage.goTo = time.Time{}
+		}
 		if m.todayPage.finished {
+			m.todayPage.finished = false
 			m.state = outcomesActive
-			m.outcomesPage = newOutcomeModel(m.Common, m.whys, m.todayPage.intentions)
+			m.outcomesPage = newOutcomeModel(m.Common, m.keys.outcomes, m.whys, m.todayPage.intentions)
 			m.outcomesPage.date = &m.date
+			cmds = append(cmds, m.GetDayReviews(m.date))
 		}
 	case outcomesActive:
 		m.outcomesPage, cmd = m.outcomesPage.Update(msg)
@@ -155,12 +339,112 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// refresh updates the input or outcomes being shown with the day's
+// intentions as they are after a change was undone or redone
+func (m *Model) refresh(msg common.IntentionMsg) tea.Cmd {
+	_, yesterday := splitPlanned(msg.Yesterday)
+	_, today := splitPlanned(msg.Today)
+	switch m.state {
+	case inputActive:
+		m.todayPage.intentions = today
+		if len(m.inputPage.carryable) > 0 {
+			m.inputPage.offerCarryOver(yesterday)
+		}
+	case outcomesActive:
+		if len(today) == 0 {
+			// there is nothing left to give outcomes for
+			m.browsing = false
+			m.date = m.CurrentDay()
+			return m.GetDaysIntentions(m.date)
+		}
+		m.outcomesPage = newOutcomeModel(m.Common, m.keys.outcomes, m.whys, today)
+		m.outcomesPage.date = &m.date
+		return m.GetDayReviews(m.date)
+	}
+	return nil
+}
+
+// goToDay shows the intentions of day read only if it is before the day the
+// page opened on, or goes back to that day otherwise
+func (m *Model) goToDay(day time.Time) tea.Cmd {
+	m.browsing = day.Before(m.home)
+	m.todayPage.setBrowsing(m.browsing, m.browsing)
+	m.todayPage.focusIndex = 0
+	m.date = day
+	if !m.browsing {
+		m.date = m.CurrentDay()
+	}
+	return m.GetDaysIntentions(m.date)
+}
+
+// toggleTimer starts a pomodoro on the focused intention if the timer is
+// idle, skips the current break, or stops the current pomodoro, recording the
+// interruption
+func (m *Model) toggleTimer() tea.Cmd {
+	switch m.timer.state {
+	case timerIdle:
+		if len(m.todayPage.intentions) == 0 {
+			return nil
+		}
+		return m.timer.startWork(m.todayPage.intentions[m.todayPage.focusIndex])
+	case timerWorking:
+		session := m.timer.session(time.Now(), false)
+		m.timer.stop()
+		return m.saveTimer(session, func(i *data.Intention) {
+			i.Interruptions++
+		})
+	default:
+		m.timer.stop()
+		return nil
+	}
+}
+
+// saveTimer saves session and applies modify to the intention it was spent
+// on, reporting the results as TimerSavedMsgs
+func (m *Model) saveTimer(session data.PomoSession, modify func(*data.Intention)) tea.Cmd {
+	return tea.Batch(common.TimerSaved(m.AddPomoSession(session)),
+		common.TimerSaved(m.updateTimedIntention(modify)))
+}
+
+// updateTimedIntention applies modify to the intention the timer was last
+// started on and saves it
+func (m *Model) updateTimedIntention(modify func(*data.Intention)) tea.Cmd {
+	for i := range m.todayPage.intentions {
+		if m.todayPage.intentions[i].ID == m.timer.intention.ID {
+			modify(&m.todayPage.intentions[i])
+			return m.UpsertIntentions([]data.Intention{m.todayPage.intentions[i]})
+		}
+	}
+	// while another day is shown, the intention is read again so that changes
+	// made to it since the timer started are kept
+	id, date := m.timer.intention.ID, m.timer.intention.Date
+	return func() tea.Msg {
+		intentions, err := m.Store.GetDaysIntentions(date)
+		if err != nil {
+			return common.ErrMsg{Error: err}
+		}
+		for i := range intentions {
+			if intentions[i].ID == id {
+				modify(&intentions[i])
+				return m.UpsertIntentions(intentions[i : i+1])()
+			}
+		}
+		return nil
+	}
+}
+
 func (m Model) View() string {
 	s := strings.Builder{}
 
 	year, month, day := m.date.Date()
 	weekday := m.date.Weekday().String()
 	fmt.Fprintf(&s, "%s %d, %s %d\n", weekday, day, month.String(), year)
+	if m.timer.state != timerIdle {
+		s.WriteString(m.timer.View() + "\n")
+	}
+	if m.message != "" {
+		s.WriteString(m.message + "\n")
+	}
 
 	switch m.state {
 	case inputActive:
@@ -174,22 +458,44 @@ func (m Model) View() string {
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, s.String())
 }
 
+// Typing reports whether keys are being typed into one of the page's inputs
+func (m *Model) Typing() bool {
+	switch m.state {
+	case inputActive:
+		return true
+	case todayActive:
+		return m.todayPage.enteringDate || m.todayPage.confirming
+	case outcomesActive:
+		section := m.outcomesPage.sections[m.outcomesPage.sectionIndex]
+		return section.input.Focused() || section.addInput.Focused()
+	}
+	return false
+}
+
 func (m *Model) SetSize(height, width int) {
 	m.height = height
 	m.width = width
 }
 
-func getCurrentDay() time.Time {
-	now := time.Now().Local()
-
-	// For our purposes, the day is considered to begin/end at 4:00AM
-	if now.Hour() < 4 {
-		now = now.AddDate(0, 0, -1)
+// splitPlanned separates the intentions planned ahead for a day that haven't
+// been confirmed yet from the rest
+func splitPlanned(intentions []data.Intention) (planned, rest []data.Intention) {
+	for _, i := range intentions {
+		if i.Planned {
+			planned = append(planned, i)
+		} else {
+			rest = append(rest, i)
+		}
 	}
-	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return planned, rest
 }
 
-func parseIntentions(whys []data.Why, input string) ([]data.Intention, error) {
+// ParseIntentions turns each non-blank line of input into an intention. Lines
+// must begin with a goal prefix such as "1)" or "0,w)", made of the codes of
+// goals in whys; a prefix that isn't a code, like "&)", leaves the intention
+// without a goal. Codes are written back in the form data.Prefix gives them,
+// so that "0, W)" becomes "0,w)".
+func ParseIntentions(whys []data.Why, input string) ([]data.Intention, error) {
 	var results []data.Intention
 
 	lines := strings.Split(input, "\n")
@@ -200,24 +506,32 @@ func parseIntentions(whys []data.Why, input string) ([]data.Intention, error) {
 		}
 		intention := data.Intention{}
 		intention.Content = line
-		prefix, _, found := strings.Cut(line, ")")
+		prefix, rest, found := strings.Cut(line, ")")
 		if !found {
 			return nil, errors.New("No goal prefix")
 		}
 		codes := strings.Split(prefix, ",")
 		for _, c := range codes {
-			whyNum, err := strconv.Atoi(string(c))
-			if err != nil {
-				// we have a non-number; treat this as an intention without an associated goal
-				// TODO: will have to rework this later archived goal codes
+			c = strings.TrimSpace(c)
+			if data.ValidCode(c) != nil {
+				// we have something which can't be a code; treat this as an
+				// intention without an associated goal
 				intention.Whys = nil
 				break
 			}
-			if !(whyNum >= 0 && whyNum <= len(whys)-1) {
-				// goal was a number, but not one that refers to an existing goal
-				return nil, errors.New("invalid goal code")
+			why := whyWithCode(whys, c)
+			if why == nil {
+				// goal was a code, but not one that refers to an existing goal
+				return nil, fmt.Errorf("invalid goal code %s", c)
+			}
+			if !hasWhy(intention.Whys, why) {
+				intention.Whys = append(intention.Whys, why)
 			}
-			intention.Whys = append(intention.Whys, &whys[whyNum])
+		}
+		if intention.Whys != nil {
+			// codes are saved as data.SplitPrefix reads them, so that they
+			// are rewritten when goals are given new ones
+			intention.Content = data.Prefix(intention.Whys) + " " + strings.TrimSpace(rest)
 		}
 		results = append(results, intention)
 	}
@@ -228,14 +542,51 @@ func parseIntentions(whys []data.Why, input string) ([]data.Intention, error) {
 	return results, nil
 }
 
-func whyBadges(whys []data.Why) string {
+// whyWithCode returns the goal in whys with code, ignoring case, or nil if
+// there is none
+func whyWithCode(whys []data.Why, code string) *data.Why {
+	for i := range whys {
+		if strings.EqualFold(whys[i].Code, code) {
+			return &whys[i]
+		}
+	}
+	return nil
+}
+
+// hasWhy reports whether why is one of whys
+func hasWhy(whys []*data.Why, why *data.Why) bool {
+	for _, w := range whys {
+		if w.ID == why.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// goalPrefix returns the prefix which ParseIntentions reads as linking an
+// intention to linked. Goals which aren't in whys, such as archived ones, are
+// left out.
+func goalPrefix(whys []data.Why, linked []*data.Why) string {
+	var shown []*data.Why
+	for _, l := range linked {
+		for i := range whys {
+			if whys[i].ID == l.ID {
+				shown = append(shown, &whys[i])
+			}
+		}
+	}
+	return data.Prefix(shown)
+}
+
+// whyBadges lays out a badge for each goal in lines no wider than width
+func whyBadges(whys []data.Why, width int) string {
 	var lines []string
 	var line strings.Builder
-	for i, why := range whys {
-		prefix := strconv.Itoa(i) + " "
+	for _, why := range whys {
+		prefix := why.Code + " "
 		whyTitle := prefix + why.Name
 		// need to use lipgloss.Width here to avoid counting the escape sequences
-		if lipgloss.Width(line.String()+whyTitle) > 70 {
+		if lipgloss.Width(line.String()+whyTitle) > width {
 			line.WriteString("\n")
 			lines = append(lines, line.String())
 			line.Reset()
@@ -1,6 +1,9 @@
 package today
 
 import (
+	"strings"
+	"time"
+
 	"github.com/benhsm/goalie/internal/data"
 	"github.com/benhsm/goalie/internal/ui/common"
 	"github.com/charmbracelet/bubbles/help"
@@ -16,30 +19,198 @@ type inputModel struct {
 	finished  bool
 	whys      *[]data.Why
 
+	// carryable holds the previous day's unfinished intentions, which may be
+	// carried over to this one, and carry which of them are to be
+	carryable   []data.Intention
+	carry       []bool
+	carryIndex  int
+	listFocused bool
+
+	// recurring and planned map the lines filled in for recurring intentions
+	// and intentions planned ahead to the rules and intentions they came from
+	recurring      map[string]uint
+	planned        map[string]data.Intention
+	recurringRules []data.RecurringIntention
+	plannedItems   []data.Intention
+	fillDay        time.Time
+	filled         string
+
+	// planning is true when intentions are being planned ahead for planDate
+	// rather than written for the current day
+	planning  bool
+	today     time.Time
+	planDate  time.Time
+	cancelled bool
+
 	help help.Model
 	keys inputKeyMap
 }
 
-func newInputModel(c common.Common) inputModel {
+func newInputModel(c common.Common, keys inputKeyMap) inputModel {
 	ti := textarea.New()
 	ti.SetHeight(10)
-	ti.SetWidth(50)
+	ti.SetWidth(c.Config.Appearance.Width)
 	ti.Placeholder = "Write some intentions for today here."
 	ti.Focus()
 
-	return inputModel{
+	m := inputModel{
 		Common:    c,
 		textInput: ti,
 		whys:      &[]data.Why{},
 		help:      help.New(),
-		keys:      inputKeys,
+		keys:      keys,
 	}
+	m.offerCarryOver(nil)
+	m.keys.Later.SetEnabled(false)
+	m.keys.Earlier.SetEnabled(false)
+	m.keys.Cancel.SetEnabled(false)
+	return m
+}
+
+// newPlanningModel returns an input for planning intentions for a day after
+// today, beginning with the day after it
+func newPlanningModel(c common.Common, keys inputKeyMap, today time.Time) inputModel {
+	m := newInputModel(c, keys)
+	m.planning = true
+	m.today = today
+	m.planDate = today.AddDate(0, 0, 1)
+	m.textInput.Placeholder = "Write some intentions to plan ahead here."
+	m.keys.Later.SetEnabled(true)
+	m.keys.Earlier.SetEnabled(true)
+	m.keys.Cancel.SetEnabled(true)
+	return m
 }
 
 func (m inputModel) Init() tea.Cmd {
+	if m.planning {
+		return tea.Batch(textarea.Blink, m.GetPlanned(m.planDate))
+	}
 	return textarea.Blink
 }
 
+// offerCarryOver offers the unfinished intentions among previous to be carried
+// over, all of them selected to begin with unless they were offered before
+func (m *inputModel) offerCarryOver(previous []data.Intention) {
+	chosen := make(map[uint]bool)
+	for i, intention := range m.carryable {
+		chosen[intention.ID] = m.carry[i]
+	}
+	m.carryable = nil
+	for _, intention := range previous {
+		if !intention.Done && !intention.Cancelled {
+			m.carryable = append(m.carryable, intention)
+		}
+	}
+	m.carry = make([]bool, len(m.carryable))
+	for i := range m.carry {
+		carry, ok := chosen[m.carryable[i].ID]
+		m.carry[i] = carry || !ok
+	}
+	m.carryIndex = 0
+	offered := len(m.carryable) > 0
+	m.keys.ChangeFocus.SetEnabled(offered)
+	m.keys.Up.SetEnabled(offered)
+	m.keys.Down.SetEnabled(offered)
+	m.keys.Carry.SetEnabled(offered)
+}
+
+// fill fills the input with a line for each intention planned for day and
+// each of rules due on it, so that they can be edited or removed before
+// being added
+func (m *inputModel) fill(planned []data.Intention, rules []data.RecurringIntention, whys []data.Why, day time.Time) {
+	m.plannedItems = planned
+	m.recurringRules = rules
+	m.fillDay = day
+	m.planned = make(map[string]data.Intention)
+	m.recurring = make(map[string]uint)
+	var lines []string
+	for _, intention := range planned {
+		m.planned[intention.Content] = intention
+		lines = append(lines, intention.Content)
+	}
+	for _, rule := range rules {
+		if !rule.Schedule.Due(day) {
+			continue
+		}
+		line := goalPrefix(whys, rule.Whys) + " " + rule.Content
+		if _, ok := m.planned[line]; ok {
+			continue
+		}
+		m.recurring[line] = rule.ID
+		lines = append(lines, line)
+	}
+	m.filled = strings.Join(lines, "\n")
+	if m.filled != "" {
+		m.textInput.SetValue(m.filled)
+	}
+}
+
+// planFor fills the input with the intentions already planned for the day
+// being planned, so that they can be edited or removed, after the lines
+// typed. Those filled in for a day planned before are left out.
+func (m *inputModel) planFor(planned []data.Intention, whys []data.Why) {
+	var typed []string
+	for _, line := range strings.Split(m.textInput.Value(), "\n") {
+		line = strings.TrimSpace(line)
+		if _, ok := m.planned[line]; !ok && line != "" {
+			typed = append(typed, line)
+		}
+	}
+	m.fill(planned, nil, whys, m.planDate)
+	var lines []string
+	if m.filled != "" {
+		lines = append(lines, m.filled)
+	}
+	m.textInput.SetValue(strings.Join(append(lines, typed...), "\n"))
+}
+
+// refill fills the input again after the goals have changed, so that the
+// goal prefixes of recurring intentions are numbered correctly, unless it has
+// been edited
+func (m *inputModel) refill(whys []data.Why) {
+	if m.filled == "" || m.textInput.Value() != m.filled {
+		return
+	}
+	m.fill(m.plannedItems, m.recurringRules, whys, m.fillDay)
+}
+
+// unconfirmed returns the planned intentions whose lines were edited or
+// removed from the input, given the intentions it was parsed into
+func (m inputModel) unconfirmed(parsed []data.Intention) []data.Intention {
+	kept := make(map[string]bool)
+	for _, intention := range parsed {
+		kept[intention.Content] = true
+	}
+	var result []data.Intention
+	for _, intention := range m.plannedItems {
+		if !kept[intention.Content] {
+			result = append(result, intention)
+		}
+	}
+	return result
+}
+
+// carried returns copies of the intentions selected to be carried over,
+// linked to the intentions they were first written as
+func (m inputModel) carried() []data.Intention {
+	var result []data.Intention
+	for i, intention := range m.carryable {
+		if !m.carry[i] {
+			continue
+		}
+		origin := intention.ID
+		if intention.CarriedFromID != nil {
+			origin = *intention.CarriedFromID
+		}
+		result = append(result, data.Intention{
+			Content:       intention.Content,
+			Whys:          intention.Whys,
+			CarriedFromID: &origin,
+		})
+	}
+	return result
+}
+
 func (m inputModel) Update(msg tea.Msg) (inputModel, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
@@ -51,6 +222,41 @@ func (m inputModel) Update(msg tea.Msg) (inputModel, tea.Cmd) {
 		switch {
 		case key.Matches(msg, m.keys.Done):
 			m.finished = true
+		case key.Matches(msg, m.keys.Cancel):
+			m.cancelled = true
+			return m, nil
+		case key.Matches(msg, m.keys.Later):
+			m.planDate = m.planDate.AddDate(0, 0, 1)
+			return m, m.GetPlanned(m.planDate)
+		case key.Matches(msg, m.keys.Earlier):
+			// planning is only for days after today
+			if m.planDate.AddDate(0, 0, -1).After(m.today) {
+				m.planDate = m.planDate.AddDate(0, 0, -1)
+				return m, m.GetPlanned(m.planDate)
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.ChangeFocus):
+			m.listFocused = !m.listFocused
+			if m.listFocused {
+				m.textInput.Blur()
+				return m, nil
+			}
+			return m, m.textInput.Focus()
+		}
+		if m.listFocused {
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.carryIndex > 0 {
+					m.carryIndex--
+				}
+			case key.Matches(msg, m.keys.Down):
+				if m.carryIndex < len(m.carryable)-1 {
+					m.carryIndex++
+				}
+			case key.Matches(msg, m.keys.Carry):
+				m.carry[m.carryIndex] = !m.carry[m.carryIndex]
+			}
+			return m, nil
 		}
 	}
 
@@ -61,20 +267,62 @@ func (m inputModel) Update(msg tea.Msg) (inputModel, tea.Cmd) {
 }
 
 func (m inputModel) View() string {
-	badges := badgeStyle.Render(whyBadges(*m.whys))
+	badges := badgeStyle.Render(whyBadges(*m.whys, m.Config.Appearance.Width+20))
 	textBox := inputStyle.Render(m.textInput.View())
 	prompt := "What are you doing towards your goals today?"
+	if m.planning {
+		prompt = "What will you do towards your goals on " + m.planDate.Format("Monday, January 2") + "?"
+	}
 	prompt = promptStyle.Render(prompt)
-	return lipgloss.JoinVertical(lipgloss.Center, badges, prompt, textBox, m.help.View(inputKeys))
+	if len(m.carryable) == 0 {
+		return lipgloss.JoinVertical(lipgloss.Center, badges, prompt, textBox, m.help.View(m.keys))
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, badges, m.carryView(), prompt, textBox, m.help.View(m.keys))
+}
+
+func (m inputModel) carryView() string {
+	var s []string
+	s = append(s, promptStyle.Render("Carry over unfinished intentions?"))
+	for i, intention := range m.carryable {
+		selected := m.listFocused && i == m.carryIndex
+		var prefix string
+		switch {
+		case m.carry[i] && selected:
+			prefix = boldCheck
+		case m.carry[i]:
+			prefix = checkBox
+		case selected:
+			prefix = selectedStyle.Render("• [ ] ")
+		default:
+			prefix = "  [ ] "
+		}
+		s = append(s, lipgloss.JoinHorizontal(lipgloss.Top, prefix, lipgloss.NewStyle().
+			Foreground(listItemStyle(intention)).
+			Width(m.Config.Appearance.Width).
+			Bold(selected).
+			Render(intention.Content)))
+	}
+	return sectionStyle.Render(lipgloss.JoinVertical(lipgloss.Left, s...))
 }
 
 type inputKeyMap struct {
 	Done        key.Binding
 	Quit        key.Binding
 	ChangeFocus key.Binding
+	// Up, Down and Carry choose which intentions to carry over while the
+	// list of them is focused
+	Up    key.Binding
+	Down  key.Binding
+	Carry key.Binding
+	// Later, Earlier and Cancel change the day being planned for, or stop
+	// planning, while planning ahead
+	Later   key.Binding
+	Earlier key.Binding
+	Cancel  key.Binding
 }
 
-var inputKeys = inputKeyMap{
+// defaultInputKeys are the bindings used unless the user configures others
+var defaultInputKeys = inputKeyMap{
 	Done: key.NewBinding(
 		key.WithKeys("ctrl+d"),
 		key.WithHelp("ctrl+d", "submit"),
@@ -87,11 +335,35 @@ var inputKeys = inputKeyMap{
 		key.WithKeys("tab"),
 		key.WithHelp("tab", "change focus"),
 	),
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "down"),
+	),
+	Carry: key.NewBinding(
+		key.WithKeys(" ", "x"),
+		key.WithHelp("space", "carry over"),
+	),
+	Later: key.NewBinding(
+		key.WithKeys("pgdown"),
+		key.WithHelp("pgdn", "later day"),
+	),
+	Earlier: key.NewBinding(
+		key.WithKeys("pgup"),
+		key.WithHelp("pgup", "earlier day"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
 }
 
 // Shorthelp is part of the key.Map interface
 func (k inputKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Done, k.Quit}
+	return []key.Binding{k.Done, k.ChangeFocus, k.Carry, k.Later, k.Earlier, k.Cancel, k.Quit}
 }
 
 // FullHelp is part of the key.Map interface
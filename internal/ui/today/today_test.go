@@ -0,0 +1,259 @@
+package today
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benhsm/goalie/internal/config"
+	"github.com/benhsm/goalie/internal/data"
+	"github.com/charmbracelet/bubbles/textarea"
+)
+
+func contents(intentions []data.Intention) []string {
+	var result []string
+	for _, i := range intentions {
+		result = append(result, i.Content)
+	}
+	return result
+}
+
+func TestSplitPlanned(t *testing.T) {
+	intentions := []data.Intention{
+		{Content: "1) write"},
+		{Content: "2) run", Planned: true},
+		{Content: "&) call", Done: true},
+		{Content: "1) read", Planned: true},
+	}
+	planned, rest := splitPlanned(intentions)
+	if got, want := contents(planned), []string{"2) run", "1) read"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("planned = %q, want %q", got, want)
+	}
+	if got, want := contents(rest), []string{"1) write", "&) call"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rest = %q, want %q", got, want)
+	}
+}
+
+func TestParseIntentions(t *testing.T) {
+	whys := []data.Why{{Code: "0"}, {Code: "w"}}
+	whys[0].ID, whys[1].ID = 1, 2
+	tests := []struct {
+		input    string
+		whys     [][]uint
+		contents []string
+		err      bool
+	}{
+		{"0) write\n\n  W) run  ", [][]uint{{1}, {2}}, []string{"0) write", "w) run"}, false},
+		{"0,w) both", [][]uint{{1, 2}}, []string{"0,w) both"}, false},
+		{"0 , W)  spaced", [][]uint{{1, 2}}, []string{"0,w) spaced"}, false},
+		{"w,0,W) repeated", [][]uint{{2, 1}}, []string{"w,0) repeated"}, false},
+		{"&) none", [][]uint{nil}, []string{"&) none"}, false},
+		{"x) unknown", nil, nil, true},
+		{"no prefix", nil, nil, true},
+		{"\n  \n", nil, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseIntentions(whys, tt.input)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseIntentions(%q) error = %v, want error %v", tt.input, err, tt.err)
+			continue
+		}
+		var ids [][]uint
+		for _, intention := range got {
+			var linked []uint
+			for _, why := range intention.Whys {
+				linked = append(linked, why.ID)
+			}
+			ids = append(ids, linked)
+		}
+		if !reflect.DeepEqual(ids, tt.whys) {
+			t.Errorf("ParseIntentions(%q) linked %v, want %v", tt.input, ids, tt.whys)
+		}
+		if got := contents(got); !reflect.DeepEqual(got, tt.contents) {
+			t.Errorf("ParseIntentions(%q) contents = %q, want %q", tt.input, got, tt.contents)
+		}
+	}
+}
+
+func TestRenameSpacedPrefix(t *testing.T) {
+	store, err := data.NewStore(filepath.Join(t.TempDir(), "goalie.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	whys := []data.Why{{Name: "Work", Code: "w"}, {Name: "Health", Code: "h", Number: 1}}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	parsed, err := ParseIntentions(whys, "w, h) walk to work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed[0].Date = day
+	if err := store.UpsertIntentions(parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	whys[0].Code = "job"
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.GetDaysIntentions(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Content != "job,h) walk to work" {
+		t.Errorf("got %+v, want the prefix rewritten to job,h)", saved)
+	}
+}
+
+func TestFillAndUnconfirmed(t *testing.T) {
+	whys := []data.Why{{Code: "w"}, {Code: "h"}}
+	whys[0].ID, whys[1].ID = 1, 2
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC) // a Monday
+	schedule, err := data.ParseSchedule("mon", day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planned := []data.Intention{
+		{Content: "w) draft", Planned: true},
+		{Content: "h) stretch", Planned: true},
+	}
+	rules := []data.RecurringIntention{
+		// already planned for the day, so not filled in twice
+		{Content: "stretch", Schedule: schedule, Whys: []*data.Why{&whys[1]}},
+		{Content: "review", Schedule: schedule, Whys: []*data.Why{&whys[0]}},
+	}
+	rules[0].ID, rules[1].ID = 1, 2
+
+	m := inputModel{textInput: textarea.New()}
+	m.fill(planned, rules, whys, day)
+	if want := "w) draft\nh) stretch\nw) review"; m.textInput.Value() != want {
+		t.Errorf("filled %q, want %q", m.textInput.Value(), want)
+	}
+	if m.recurring["w) review"] != 2 || len(m.recurring) != 1 {
+		t.Errorf("recurring = %v, want only w) review from rule 2", m.recurring)
+	}
+
+	// nothing is due the day after
+	m.fill(nil, rules, whys, day.AddDate(0, 0, 1))
+	if m.textInput.Value() != "w) draft\nh) stretch\nw) review" || m.filled != "" {
+		t.Errorf("filling nothing replaced the input with %q", m.textInput.Value())
+	}
+
+	m.fill(planned, nil, whys, day)
+	parsed := []data.Intention{{Content: "w) draft"}, {Content: "h) stretch longer"}}
+	if got, want := contents(m.unconfirmed(parsed)), []string{"h) stretch"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unconfirmed = %q, want %q", got, want)
+	}
+}
+
+func TestPlanFor(t *testing.T) {
+	m := inputModel{textInput: textarea.New(), planning: true}
+	m.planDate = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	m.planFor([]data.Intention{{Content: "w) draft", Planned: true}}, nil)
+	if got := m.textInput.Value(); got != "w) draft" {
+		t.Fatalf("filled %q, want w) draft", got)
+	}
+
+	// lines typed follow the next day's planned intentions, without those of
+	// the day before
+	m.textInput.SetValue("w) draft\nh) typed")
+	m.planDate = m.planDate.AddDate(0, 0, 1)
+	m.planFor([]data.Intention{{Content: "h) run", Planned: true}}, nil)
+	if got, want := m.textInput.Value(), "h) run\nh) typed"; got != want {
+		t.Errorf("filled %q, want %q", got, want)
+	}
+
+	m.textInput.SetValue("h) run")
+	m.planDate = m.planDate.AddDate(0, 0, 1)
+	m.planFor(nil, nil)
+	if got := m.textInput.Value(); got != "" {
+		t.Errorf("filled %q for a day with nothing planned", got)
+	}
+}
+
+func TestPomodoroTimer(t *testing.T) {
+	timer := newPomodoroTimer(config.PomodoroConfig{
+		Work: 25 * time.Minute, ShortBreak: 5 * time.Minute, LongBreak: 15 * time.Minute, LongBreakEvery: 2,
+	})
+	intention := data.Intention{Content: "w) draft"}
+	intention.ID = 3
+
+	timer.startWork(intention)
+	id := timer.id
+	if timer.state != timerWorking || timer.deadline.Sub(timer.started) != 25*time.Minute || timer.expired() {
+		t.Errorf("started work as %+v", timer)
+	}
+	end := timer.started.Add(10 * time.Minute)
+	session := timer.session(end, false)
+	if session.IntentionID != 3 || !session.Start.Equal(timer.started) || !session.End.Equal(end) ||
+		session.Duration != 10*time.Minute || session.Completed {
+		t.Errorf("got session %+v", session)
+	}
+
+	// breaks are short, but long after every second pomodoro
+	for i, want := range []time.Duration{5 * time.Minute, 15 * time.Minute, 5 * time.Minute} {
+		timer.startBreak()
+		if got := timer.deadline.Sub(timer.now); timer.state != timerBreak || got != want {
+			t.Errorf("break %d lasts %s, want %s", i+1, got, want)
+		}
+	}
+	if timer.id == id {
+		t.Error("a new countdown kept the id of the one before")
+	}
+
+	// stopping starts counting towards a long break again
+	timer.stop()
+	timer.startBreak()
+	if timer.state != timerBreak || timer.deadline.Sub(timer.now) != 5*time.Minute {
+		t.Errorf("the first break after stopping isn't a short one: %+v", timer)
+	}
+}
+
+func TestCarryOver(t *testing.T) {
+	first := uint(1)
+	previous := []data.Intention{
+		{Content: "w) done", Done: true},
+		{Content: "w) cancelled", Cancelled: true},
+		{Content: "w) draft"},
+		// itself carried over from intention 1
+		{Content: "h) stretch", CarriedFromID: &first},
+	}
+	for i := range previous {
+		previous[i].ID = uint(i + 2)
+	}
+
+	m := inputModel{keys: defaultInputKeys}
+	m.offerCarryOver(previous)
+	if got := contents(m.carryable); !reflect.DeepEqual(got, []string{"w) draft", "h) stretch"}) {
+		t.Fatalf("offered %q, want the unfinished intentions", got)
+	}
+	if !reflect.DeepEqual(m.carry, []bool{true, true}) || !m.keys.Carry.Enabled() {
+		t.Errorf("carry = %v, want every offered intention chosen", m.carry)
+	}
+
+	// choices are kept when the intentions are offered again, with any new
+	// ones chosen
+	m.carry[0] = false
+	m.offerCarryOver(append(previous, data.Intention{ID: 6, Content: "&) new"}))
+	if !reflect.DeepEqual(m.carry, []bool{false, true, true}) {
+		t.Errorf("carry = %v after offering again, want the first left behind", m.carry)
+	}
+
+	carried := m.carried()
+	if got := contents(carried); !reflect.DeepEqual(got, []string{"h) stretch", "&) new"}) {
+		t.Fatalf("carried %q", got)
+	}
+	// copies are linked to the intention first written, however often they
+	// were carried over
+	if carried[0].ID != 0 || *carried[0].CarriedFromID != 1 || *carried[1].CarriedFromID != 6 {
+		t.Errorf("carried %+v, want new copies linked to intentions 1 and 6", carried)
+	}
+
+	m.offerCarryOver(nil)
+	if len(m.carryable) != 0 || m.keys.Carry.Enabled() {
+		t.Error("offering nothing left the list to choose from")
+	}
+}
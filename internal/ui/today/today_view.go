@@ -17,7 +17,6 @@ import (
 var (
 	listBoxStyle = lipgloss.NewStyle().
 			Height(10).
-			Width(50).
 			Border(lipgloss.RoundedBorder(), true).
 			Margin(1, 0, 0, 0)
 	selectedStyle = lipgloss.NewStyle().
@@ -45,7 +44,8 @@ var (
 	pomos = func(i data.Intention) string {
 		return " " + strings.Repeat("🍅", i.Pomos)
 	}
-	listItemRender = func(i data.Intention, selected bool) string {
+	// the item renderers fill width columns, including the checkbox prefix
+	listItemRender = func(i data.Intention, selected bool, width int) string {
 		color := listItemStyle(i)
 		var prefix string
 		if selected {
@@ -55,11 +55,11 @@ var (
 		}
 		return lipgloss.JoinHorizontal(lipgloss.Top, prefix, lipgloss.NewStyle().
 			Foreground(color).
-			Width(44).
+			Width(width).
 			Bold(selected).
 			Render(i.Content+pomos(i)))
 	}
-	doneItemRender = func(i data.Intention, selected bool) string {
+	doneItemRender = func(i data.Intention, selected bool, width int) string {
 		color := listItemStyle(i)
 		var prefix string
 		if selected {
@@ -69,12 +69,12 @@ var (
 		}
 		return lipgloss.JoinHorizontal(lipgloss.Top, prefix, lipgloss.NewStyle().
 			Foreground(color).
-			Width(44).
+			Width(width).
 			Bold(selected).
 			Strikethrough(true).
 			Render(i.Content+pomos(i)))
 	}
-	cancelledRender = func(i data.Intention, selected bool) string {
+	cancelledRender = func(i data.Intention, selected bool, width int) string {
 		var prefix string
 		if selected {
 			prefix = selectedCancelled
@@ -85,7 +85,7 @@ var (
 			Foreground(lipgloss.AdaptiveColor{Light: "#969B86", Dark: "#696969"}).
 			//			Background(lipgloss.AdaptiveColor{Light: "255", Dark: "0"}).
 			Strikethrough(true).
-			Width(44).
+			Width(width).
 			Bold(selected).
 			Render(i.Content))
 	}
@@ -98,9 +98,24 @@ type todayModel struct {
 	input      textinput.Model
 	date       *time.Time
 
-	focusIndex int
-	adding     bool
-	finished   bool
+	focusIndex  int
+	adding      bool
+	planning    bool
+	finished    bool
+	toggleTimer bool
+	// planned is the number of intentions planned for the next day
+	planned int
+
+	// browsing is true when showing a day before the current one, which is
+	// read only until editing it is confirmed
+	browsing   bool
+	readOnly   bool
+	confirming bool
+	// goTo is the day asked to be shown, if any, which is typed into input
+	// while enteringDate
+	goTo         time.Time
+	enteringDate bool
+	dateErr      error
 
 	height int
 	width  int
@@ -109,13 +124,37 @@ type todayModel struct {
 	help help.Model
 }
 
-func newTodayModel(c common.Common) todayModel {
-	return todayModel{
+func newTodayModel(c common.Common, keys todayKeyMap) todayModel {
+	input := textinput.New()
+	input.Placeholder = "YYYY-MM-DD"
+	input.CharLimit = len(dateLayout)
+	m := todayModel{
 		common: c,
 		whys:   &[]data.Why{},
-		keys:   todayKeys,
+		input:  input,
+		keys:   keys,
 		help:   help.New(),
 	}
+	m.setBrowsing(false, false)
+	return m
+}
+
+const dateLayout = "2006-01-02"
+
+// setBrowsing enables the keys which apply to the kind of day being shown.
+// Days other than the current one can't be planned from or timed, and read
+// only days can't be changed until editing them is confirmed.
+func (m *todayModel) setBrowsing(browsing, readOnly bool) {
+	m.browsing = browsing
+	m.readOnly = readOnly
+	for _, k := range []*key.Binding{&m.keys.ShiftUp, &m.keys.ShiftDown, &m.keys.Cancel,
+		&m.keys.Add, &m.keys.MarkDone, &m.keys.AssignPomo, &m.keys.UnassignPomo, &m.keys.EndDay} {
+		k.SetEnabled(!readOnly)
+	}
+	m.keys.Plan.SetEnabled(!browsing)
+	m.keys.Timer.SetEnabled(!browsing)
+	m.keys.Edit.SetEnabled(readOnly)
+	m.keys.Confirm.SetEnabled(false)
 }
 
 func (m *todayModel) Init() tea.Cmd {
@@ -130,22 +169,50 @@ func (m todayModel) Update(msg tea.Msg) (todayModel, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.SetSize(msg.Height, msg.Width)
 	case tea.KeyMsg:
+		if m.enteringDate {
+			return m.updateDateInput(msg)
+		}
+		if m.confirming {
+			// any key other than the confirmation leaves the day read only
+			m.confirming = false
+			if key.Matches(msg, m.keys.Confirm) {
+				m.setBrowsing(true, false)
+			}
+			m.keys.Confirm.SetEnabled(false)
+			return m, nil
+		}
 		switch {
+		case key.Matches(msg, m.keys.PrevDay):
+			m.goTo = m.date.AddDate(0, 0, -1)
+		case key.Matches(msg, m.keys.NextDay):
+			m.goTo = m.date.AddDate(0, 0, 1)
+		case key.Matches(msg, m.keys.GoTo):
+			m.enteringDate = true
+			m.dateErr = nil
+			m.input.Reset()
+			return m, m.input.Focus()
+		case key.Matches(msg, m.keys.Edit):
+			m.confirming = true
+			m.keys.Confirm.SetEnabled(true)
 		case key.Matches(msg, m.keys.Down):
 			m.focusIndex++
 		case key.Matches(msg, m.keys.Up):
 			m.focusIndex--
 		case key.Matches(msg, m.keys.Add):
 			m.adding = true
+		case key.Matches(msg, m.keys.Plan):
+			m.planning = true
 		case key.Matches(msg, m.keys.EndDay):
 			m.finished = true
+		case key.Matches(msg, m.keys.Timer):
+			m.toggleTimer = true
 		case key.Matches(msg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
 		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
 
 		// keys that modify the intention list
-		case key.Matches(msg, m.keys.ShiftUp, m.keys.ShiftDown,
+		case len(m.intentions) > 0 && key.Matches(msg, m.keys.ShiftUp, m.keys.ShiftDown,
 			m.keys.MarkDone, m.keys.Cancel, m.keys.AssignPomo, m.keys.UnassignPomo):
 			switch {
 			case key.Matches(msg, m.keys.ShiftDown):
@@ -170,7 +237,7 @@ func (m todayModel) Update(msg tea.Msg) (todayModel, tea.Cmd) {
 				m.intentions[m.focusIndex].Cancelled = !m.intentions[m.focusIndex].Cancelled
 			case key.Matches(msg, m.keys.MarkDone):
 				m.intentions[m.focusIndex].Done = !m.intentions[m.focusIndex].Done
-			case key.Matches(msg, m.keys.AssignPomo, outcomeKeys.UnassignPomo):
+			case key.Matches(msg, m.keys.AssignPomo, m.keys.UnassignPomo):
 				if key.Matches(msg, m.keys.AssignPomo) {
 					m.intentions[m.focusIndex].Pomos++
 				}
@@ -192,6 +259,35 @@ func (m todayModel) Update(msg tea.Msg) (todayModel, tea.Cmd) {
 	return m, tea.Sequence(cmds...)
 }
 
+// updateDateInput handles keys while a date to go to is being typed. An empty
+// date goes back to the current day.
+func (m todayModel) updateDateInput(msg tea.KeyMsg) (todayModel, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.enteringDate = false
+		m.input.Blur()
+		return m, nil
+	case tea.KeyEnter:
+		value := strings.TrimSpace(m.input.Value())
+		day := m.common.CurrentDay()
+		if value != "" {
+			var err error
+			day, err = time.ParseInLocation(dateLayout, value, time.UTC)
+			if err != nil {
+				m.dateErr = fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+				return m, nil
+			}
+		}
+		m.goTo = day
+		m.enteringDate = false
+		m.input.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
 func (m *todayModel) View() string {
 	var s []string
 	var totalIntentions int
@@ -205,6 +301,25 @@ func (m *todayModel) View() string {
 		}
 	}
 	prompt := promptStyle.Render(fmt.Sprintf("\n%d intentions for today, %d/%d done", totalIntentions, doneIntentions, totalIntentions))
+	if m.browsing {
+		prompt = promptStyle.Render(fmt.Sprintf("\n%d intentions, %d/%d done", totalIntentions, doneIntentions, totalIntentions))
+		if m.readOnly {
+			prompt += " (read only)"
+		}
+	}
+	if m.planned > 0 {
+		prompt += fmt.Sprintf("\n%d planned for tomorrow", m.planned)
+	}
+	switch {
+	case m.enteringDate:
+		prompt += "\nGo to date: " + m.input.View()
+		if m.dateErr != nil {
+			prompt += "\n" + m.dateErr.Error()
+		}
+	case m.confirming:
+		prompt += "\nEdit the intentions and outcomes of this day? (y/n)"
+	}
+	width := m.common.Config.Appearance.Width
 	for i, intention := range m.intentions {
 		var renderedIntention string
 		selected := false
@@ -212,18 +327,18 @@ func (m *todayModel) View() string {
 			selected = true
 		}
 		if intention.Cancelled {
-			renderedIntention = cancelledRender(intention, selected)
+			renderedIntention = cancelledRender(intention, selected, width-6)
 		} else if intention.Done {
-			renderedIntention = doneItemRender(intention, selected)
+			renderedIntention = doneItemRender(intention, selected, width-6)
 		} else {
-			renderedIntention = listItemRender(intention, selected)
+			renderedIntention = listItemRender(intention, selected, width-6)
 		}
 		s = append(s, renderedIntention)
 	}
 	listBox := lipgloss.JoinVertical(lipgloss.Left, s...)
-	listBox = listBoxStyle.Render(listBox)
-	badges := badgeStyle.Render(whyBadges(*m.whys))
-	return lipgloss.JoinVertical(lipgloss.Center, prompt, listBox, badges, m.help.View(todayKeys))
+	listBox = listBoxStyle.Copy().Width(width).Render(listBox)
+	badges := badgeStyle.Render(whyBadges(*m.whys, width+20))
+	return lipgloss.JoinVertical(lipgloss.Center, prompt, listBox, badges, m.help.View(m.keys))
 }
 
 func (m *todayModel) SetSize(height, width int) {
@@ -247,13 +362,21 @@ type todayKeyMap struct {
 	Quit         key.Binding
 	Cancel       key.Binding
 	Add          key.Binding
+	Plan         key.Binding
 	MarkDone     key.Binding
 	AssignPomo   key.Binding
 	UnassignPomo key.Binding
+	Timer        key.Binding
 	EndDay       key.Binding
+	PrevDay      key.Binding
+	NextDay      key.Binding
+	GoTo         key.Binding
+	Edit         key.Binding
+	Confirm      key.Binding
 }
 
-var todayKeys = todayKeyMap{
+// defaultTodayKeys are the bindings used unless the user configures others
+var defaultTodayKeys = todayKeyMap{
 	Up: key.NewBinding(
 		key.WithKeys("up", "k"),
 		key.WithHelp("↑/k", "move up"),
@@ -275,7 +398,7 @@ var todayKeys = todayKeyMap{
 		key.WithHelp("?", "toggle help"),
 	),
 	Quit: key.NewBinding(
-		key.WithKeys("q", "esc", "ctrl+c"),
+		key.WithKeys("q", "ctrl+c"),
 		key.WithHelp("q", "quit"),
 	),
 	Cancel: key.NewBinding(
@@ -286,6 +409,10 @@ var todayKeys = todayKeyMap{
 		key.WithKeys("a"),
 		key.WithHelp("a", "add item"),
 	),
+	Plan: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "plan ahead"),
+	),
 	MarkDone: key.NewBinding(
 		key.WithKeys(" ", "enter"),
 		key.WithHelp("space/enter", "mark done"),
@@ -298,24 +425,49 @@ var todayKeys = todayKeyMap{
 		key.WithKeys("P"),
 		key.WithHelp("P", "unassign pomo"),
 	),
+	Timer: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "start/stop timer"),
+	),
 	EndDay: key.NewBinding(
 		key.WithKeys("ctrl+d"),
 		key.WithHelp("ctrl+d", "end day"),
 	),
+	PrevDay: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous day"),
+	),
+	NextDay: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next day"),
+	),
+	GoTo: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "go to date"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit day"),
+	),
+	Confirm: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "confirm"),
+	),
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view. It's part
 // of the key.Map interface.
 func (k todayKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Help, k.Quit}
+	return []key.Binding{k.Edit, k.Help, k.Quit}
 }
 
 // FullHelp returns keybindings for the expanded help view. It's part of the
 // key.Map interface.
 func (k todayKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down, k.ShiftDown, k.ShiftUp},            // first column
-		{k.Add, k.MarkDone, k.AssignPomo, k.UnassignPomo}, // second column
-		{k.Cancel, k.EndDay, k.Help, k.Quit},
+		{k.Up, k.Down, k.ShiftDown, k.ShiftUp},                    // first column
+		{k.Add, k.Plan, k.MarkDone, k.AssignPomo, k.UnassignPomo}, // second column
+		{k.Cancel, k.Timer, k.EndDay, k.Help, k.Quit},
+		{k.PrevDay, k.NextDay, k.GoTo, k.Edit},
 	}
 }
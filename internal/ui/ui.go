@@ -1,9 +1,15 @@
 package ui
 
 import (
+	"github.com/benhsm/goalie/internal/config"
+	"github.com/benhsm/goalie/internal/data"
 	"github.com/benhsm/goalie/internal/ui/common"
+	"github.com/benhsm/goalie/internal/ui/review"
+	"github.com/benhsm/goalie/internal/ui/stats"
+	"github.com/benhsm/goalie/internal/ui/timeline"
 	"github.com/benhsm/goalie/internal/ui/today"
 	whys "github.com/benhsm/goalie/internal/ui/whys"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -12,8 +18,9 @@ type page int
 const (
 	todayPage page = iota
 	whysPage
-
-// reviewsPage
+	timelinePage
+	reviewsPage
+	statsPage
 )
 
 // Model is the main UI model
@@ -21,15 +28,20 @@ type Model struct {
 	common.Common
 	pages      []common.Component
 	activePage page
+	keys       keyMap
 }
 
-func New() Model {
-	c := common.NewCommon()
-	result := Model{Common: c}
-	result.pages = make([]common.Component, 2)
+func New(store data.Store, cfg config.Config) Model {
+	c := common.NewCommon(store, cfg)
+	result := Model{Common: c, keys: defaultKeys}
+	c.Keys.Register(common.GlobalKeys, &result.keys)
+	result.pages = make([]common.Component, 5)
 
 	result.pages[whysPage] = whys.New(c)
 	result.pages[todayPage] = today.New(c)
+	result.pages[timelinePage] = timeline.New(c)
+	result.pages[reviewsPage] = review.New(c)
+	result.pages[statsPage] = stats.New(c)
 	return result
 }
 
@@ -58,6 +70,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 	case common.WhyDataMsg:
+		if msg.Status != data.Active {
+			// only the goals page, which asked for them, uses other goals
+			p, cmd := m.pages[whysPage].Update(msg)
+			m.pages[whysPage] = p.(common.Component)
+			return m, cmd
+		}
 		// All pages need to be updated with current whys
 		for page := range m.pages {
 			p, cmd := m.pages[page].Update(msg)
@@ -71,16 +89,42 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// user can add some
 			m.activePage = whysPage
 		}
+	case common.TimerTickMsg, common.TimerSavedMsg:
+		// the pomodoro timer keeps running whichever page is active
+		p, cmd := m.pages[todayPage].Update(msg)
+		m.pages[todayPage] = p.(common.Component)
+		return m, cmd
+	case common.UndoMsg:
+		// whatever was undone may be shown on any page, and the goals page
+		// reads the goals again for all of them
+		for page := range m.pages {
+			p, cmd := m.pages[page].Update(msg)
+			m.pages[page] = p.(common.Component)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+		return m, tea.Batch(cmds...)
 	case tea.KeyMsg:
-		if msg.String() == "f1" {
-			m.activePage = whysPage
-			cmd := m.pages[m.activePage].Init()
-			cmds = append(cmds, cmd)
+		if t, ok := m.pages[m.activePage].(common.Typer); !ok || !t.Typing() {
+			switch {
+			case key.Matches(msg, m.keys.Undo):
+				return m, m.Undo()
+			case key.Matches(msg, m.keys.Redo):
+				return m, m.Redo()
+			}
 		}
-		if msg.String() == "f2" {
-			m.activePage = todayPage
-			cmd := m.pages[m.activePage].Init()
-			cmds = append(cmds, cmd)
+		switch {
+		case key.Matches(msg, m.keys.Goals):
+			cmds = append(cmds, m.switchTo(whysPage))
+		case key.Matches(msg, m.keys.Today):
+			cmds = append(cmds, m.switchTo(todayPage))
+		case key.Matches(msg, m.keys.Timeline):
+			cmds = append(cmds, m.switchTo(timelinePage))
+		case key.Matches(msg, m.keys.Reviews):
+			cmds = append(cmds, m.switchTo(reviewsPage))
+		case key.Matches(msg, m.keys.Stats):
+			cmds = append(cmds, m.switchTo(statsPage))
 		}
 	}
 	pageModel, cmd := m.pages[m.activePage].Update(msg)
@@ -91,6 +135,56 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// switchTo makes p the active page, returning the command that initialises it
+func (m *Model) switchTo(p page) tea.Cmd {
+	m.activePage = p
+	return m.pages[p].Init()
+}
+
 func (m Model) View() string {
 	return m.pages[m.activePage].View()
 }
+
+// keyMap holds the bindings which switch between pages, and undo and redo
+// changes, from anywhere
+type keyMap struct {
+	Goals    key.Binding
+	Today    key.Binding
+	Timeline key.Binding
+	Reviews  key.Binding
+	Stats    key.Binding
+	Undo     key.Binding
+	Redo     key.Binding
+}
+
+// defaultKeys are the bindings used unless the user configures others
+var defaultKeys = keyMap{
+	Goals: key.NewBinding(
+		key.WithKeys("f1"),
+		key.WithHelp("f1", "goals"),
+	),
+	Today: key.NewBinding(
+		key.WithKeys("f2"),
+		key.WithHelp("f2", "today"),
+	),
+	Timeline: key.NewBinding(
+		key.WithKeys("f3"),
+		key.WithHelp("f3", "timeline"),
+	),
+	Reviews: key.NewBinding(
+		key.WithKeys("f4"),
+		key.WithHelp("f4", "reviews"),
+	),
+	Stats: key.NewBinding(
+		key.WithKeys("f5"),
+		key.WithHelp("f5", "stats"),
+	),
+	Undo: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "undo"),
+	),
+	Redo: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "redo"),
+	),
+}
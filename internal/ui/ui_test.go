@@ -0,0 +1,497 @@
+package ui
+
+import (
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benhsm/goalie/internal/config"
+	"github.com/benhsm/goalie/internal/data"
+	"github.com/benhsm/goalie/internal/ui/common"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// driver feeds messages to the whole TUI as bubbletea would, running the
+// commands returned until no more messages come of them
+type driver struct {
+	t     *testing.T
+	m     tea.Model
+	store data.Store
+	today time.Time
+}
+
+// newDriver starts the TUI on a store holding two goals, with an intention
+// for each for the current day
+func newDriver(t *testing.T) *driver {
+	t.Helper()
+	return newDriverWith(t, config.Default())
+}
+
+// newDriverWith starts the TUI as newDriver does, configured by cfg
+func newDriverWith(t *testing.T, cfg config.Config) *driver {
+	t.Helper()
+	store, err := data.NewStore(filepath.Join(t.TempDir(), "goalie.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &driver{t: t, store: store, today: cfg.Day.CurrentDay()}
+	whys := []data.Why{{Name: "Work", Code: "w"}, {Name: "Health", Code: "h", Number: 1}}
+	if err := store.UpsertWhys(whys); err != nil {
+		t.Fatal(err)
+	}
+	d.add(d.today, "w) write", "h) run")
+
+	m := New(store, cfg)
+	d.m = m
+	d.send(tea.WindowSizeMsg{Width: 120, Height: 60})
+	d.run(m.Init())
+	return d
+}
+
+// add saves intentions with contents for day, linked to the goals in their
+// prefixes
+func (d *driver) add(day time.Time, contents ...string) {
+	d.t.Helper()
+	whys, err := d.store.GetWhys(data.All)
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	var intentions []data.Intention
+	for i, content := range contents {
+		intention := data.Intention{Date: day, Content: content, Position: i}
+		codes, _, _ := data.SplitPrefix(content)
+		for _, code := range codes {
+			for j := range whys {
+				if whys[j].Code == code {
+					intention.Whys = append(intention.Whys, &whys[j])
+				}
+			}
+		}
+		intentions = append(intentions, intention)
+	}
+	if err := d.store.UpsertIntentions(intentions); err != nil {
+		d.t.Fatal(err)
+	}
+}
+
+func (d *driver) send(msg tea.Msg) {
+	m, cmd := d.m.Update(msg)
+	d.m = m
+	d.run(cmd)
+}
+
+// press sends a key for each of keys, which are either the names of keys
+// such as "f1" or "space", or runes typed
+func (d *driver) press(keys ...string) {
+	special := map[string]tea.KeyType{
+		"f1": tea.KeyF1, "f2": tea.KeyF2, "f3": tea.KeyF3, "f4": tea.KeyF4, "f5": tea.KeyF5,
+		"space": tea.KeySpace, "enter": tea.KeyEnter, "esc": tea.KeyEsc, "tab": tea.KeyTab,
+		"ctrl+d": tea.KeyCtrlD, "ctrl+r": tea.KeyCtrlR, "ctrl+u": tea.KeyCtrlU, "pgup": tea.KeyPgUp, "pgdown": tea.KeyPgDown,
+	}
+	for _, k := range keys {
+		if t, ok := special[k]; ok {
+			d.send(tea.KeyMsg{Type: t})
+		} else {
+			d.send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		}
+	}
+}
+
+func (d *driver) run(cmd tea.Cmd) {
+	for _, msg := range collect(cmd) {
+		d.send(msg)
+	}
+}
+
+// collect runs cmd, and those batched in what it returns, giving up on those
+// which wait, such as the timer's ticks and cursor blinks
+func collect(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+	select {
+	case msg := <-done:
+		// batches, and sequences, whose type isn't exported, are run in turn
+		if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice && v.Type().Elem() == reflect.TypeOf(cmd) {
+			var msgs []tea.Msg
+			for i := 0; i < v.Len(); i++ {
+				msgs = append(msgs, collect(v.Index(i).Interface().(tea.Cmd))...)
+			}
+			return msgs
+		}
+		if msg == nil {
+			return nil
+		}
+		return []tea.Msg{msg}
+	case <-time.After(100 * time.Millisecond):
+		return nil
+	}
+}
+
+var ansiStyle = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// view returns what is shown, without styles
+func (d *driver) view() string {
+	return ansiStyle.ReplaceAllString(d.m.View(), "")
+}
+
+// shows fails the test unless the view contains each of want
+func (d *driver) shows(want ...string) {
+	d.t.Helper()
+	view := d.view()
+	for _, w := range want {
+		if !strings.Contains(view, w) {
+			d.t.Errorf("view doesn't contain %q:\n%s", w, view)
+		}
+	}
+}
+
+// hides fails the test if the view contains any of unwanted
+func (d *driver) hides(unwanted ...string) {
+	d.t.Helper()
+	view := d.view()
+	for _, u := range unwanted {
+		if strings.Contains(view, u) {
+			d.t.Errorf("view contains %q:\n%s", u, view)
+		}
+	}
+}
+
+// review gives outcomes for the intentions saved for day
+func (d *driver) review(day time.Time) {
+	d.t.Helper()
+	intentions, err := d.store.GetDaysIntentions(day)
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	for i := range intentions {
+		intentions[i].Outcome = true
+	}
+	if err := d.store.UpsertIntentions(intentions); err != nil {
+		d.t.Fatal(err)
+	}
+}
+
+// intention returns the intention saved for day with content
+func (d *driver) intention(day time.Time, content string) data.Intention {
+	d.t.Helper()
+	intentions, err := d.store.GetDaysIntentions(day)
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	for _, i := range intentions {
+		if i.Content == content {
+			return i
+		}
+	}
+	d.t.Fatalf("no intention %q on %s", content, day.Format("2006-01-02"))
+	return data.Intention{}
+}
+
+func TestPomodoroEndsOnAnotherPage(t *testing.T) {
+	d := newDriver(t)
+	d.shows("2 intentions for today")
+	d.press("t", "f1", "J")
+	d.shows("Unsaved modifications.")
+
+	// the first countdown of the timer ends while the goals page is active
+	d.send(common.TimerTickMsg{ID: 1, Time: time.Now().Add(time.Hour)})
+	d.shows("Unsaved modifications.")
+	if got := d.intention(d.today, "w) write").Pomos; got != 1 {
+		t.Errorf("the finished pomodoro left %d pomos, want 1", got)
+	}
+	sessions, err := d.store.GetPomoSessionsBetween(d.today, d.today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || !sessions[0].Completed {
+		t.Errorf("got sessions %+v, want one completed", sessions)
+	}
+}
+
+func TestBrowsingAcrossPages(t *testing.T) {
+	d := newDriver(t)
+	yesterday := d.today.AddDate(0, 0, -1)
+	d.add(yesterday, "w) draft")
+	d.review(yesterday)
+
+	d.press("[")
+	d.shows("1 intentions, 0/1 done (read only)")
+	d.press("f1", "f2")
+	d.shows("1 intentions, 0/1 done (read only)")
+	d.hides("intentions for today")
+	d.press("space")
+	if d.intention(yesterday, "w) draft").Done {
+		t.Error("marked an intention done on a read only day")
+	}
+
+	// editing the day is confirmed again after coming back to it
+	d.press("e", "y", "f1", "f2")
+	d.shows("(read only)")
+	d.press("e", "y", "space")
+	if !d.intention(yesterday, "w) draft").Done {
+		t.Error("couldn't mark an intention done once editing was confirmed")
+	}
+}
+
+func TestPlanningAhead(t *testing.T) {
+	d := newDriver(t)
+	tomorrow := d.today.AddDate(0, 0, 1)
+	d.add(tomorrow, "h) stretch", "w) plan")
+	for _, content := range []string{"h) stretch", "w) plan"} {
+		i := d.intention(tomorrow, content)
+		i.Planned = true
+		if err := d.store.UpsertIntentions([]data.Intention{i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stretch := d.intention(tomorrow, "h) stretch")
+
+	// what is planned for each day is filled in as it is chosen
+	d.press("n")
+	d.shows("h) stretch", "w) plan")
+	d.press("pgdown")
+	d.hides("h) stretch")
+	d.press("pgup")
+	d.shows("h) stretch")
+
+	// keep one, and replace the other, on the last line, with another
+	d.press("ctrl+u", "w) more", "ctrl+d")
+	intentions, err := d.store.GetDaysIntentions(tomorrow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]data.Intention)
+	for _, i := range intentions {
+		got[i.Content] = i
+	}
+	if len(got) != 2 || got["h) stretch"].ID != stretch.ID || got["h) stretch"].Position != 0 ||
+		got["w) more"].Position != 1 || !got["w) more"].Planned {
+		t.Errorf("got %+v, want h) stretch kept first and w) more planned after it", intentions)
+	}
+}
+
+func TestTimelineLeavesOutPlanned(t *testing.T) {
+	d := newDriver(t)
+	d.add(d.today, "w) later")
+	later := d.intention(d.today, "w) later")
+	later.Planned = true
+	if err := d.store.UpsertIntentions([]data.Intention{later}); err != nil {
+		t.Fatal(err)
+	}
+	d.press("f3")
+	d.shows("w) write")
+	d.hides("w) later")
+}
+
+func TestReviewArchivedInMisc(t *testing.T) {
+	d := newDriver(t)
+	old := []data.Why{{Name: "Old", Code: "o", Number: 2, Archived: true}}
+	if err := d.store.UpsertWhys(old); err != nil {
+		t.Fatal(err)
+	}
+	d.add(d.today, "o) legacy")
+	legacy := d.intention(d.today, "o) legacy")
+	legacy.Done, legacy.Pomos = true, 3
+	if err := d.store.UpsertIntentions([]data.Intention{legacy}); err != nil {
+		t.Fatal(err)
+	}
+
+	d.press("f4")
+	for _, line := range strings.Split(d.view(), "\n") {
+		if strings.Contains(line, "MISC") {
+			if !strings.Contains(line, "100% done") || !strings.Contains(line, "3 pomos") {
+				t.Errorf("intentions of archived goals aren't reviewed with misc ones: %q", line)
+			}
+			return
+		}
+	}
+	t.Errorf("no misc section:\n%s", d.view())
+}
+
+func TestArchivedGoalsStayOnGoalsPage(t *testing.T) {
+	d := newDriver(t)
+	old := []data.Why{{Name: "Old", Code: "o", Number: 2, Archived: true}}
+	if err := d.store.UpsertWhys(old); err != nil {
+		t.Fatal(err)
+	}
+	// the goals page reads active and archived goals each time it is shown
+	d.press("f1", "v")
+	d.shows("Old")
+	d.press("f2")
+	d.shows("Work", "Health")
+	d.hides("Old")
+}
+
+func TestUndoRemovingPlanned(t *testing.T) {
+	d := newDriver(t)
+	tomorrow := d.today.AddDate(0, 0, 1)
+	d.add(tomorrow, "h) stretch", "w) plan")
+	for _, content := range []string{"h) stretch", "w) plan"} {
+		i := d.intention(tomorrow, content)
+		i.Planned = true
+		if err := d.store.UpsertIntentions([]data.Intention{i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plan := d.intention(tomorrow, "w) plan")
+
+	// removing what was planned is undone with the rest of the planning
+	d.press("n", "ctrl+u", "ctrl+d")
+	if intentions, _ := d.store.GetDaysIntentions(tomorrow); len(intentions) != 1 {
+		t.Fatalf("got %+v, want w) plan deleted", intentions)
+	}
+	d.press("u")
+	d.shows("undid deleting 1 intention")
+	if got := d.intention(tomorrow, "w) plan"); got.ID != plan.ID || !got.Planned {
+		t.Errorf("got %+v, want w) plan restored as planned", got)
+	}
+	d.press("ctrl+r")
+	if intentions, _ := d.store.GetDaysIntentions(tomorrow); len(intentions) != 1 {
+		t.Errorf("got %+v after redoing, want w) plan deleted again", intentions)
+	}
+}
+
+func TestUndoMarkingDone(t *testing.T) {
+	d := newDriver(t)
+	d.shows("0/2 done")
+	d.press("space")
+	d.shows("1/2 done")
+	// what is shown is read again once undone
+	d.press("u")
+	d.shows("0/2 done")
+	if d.intention(d.today, "w) write").Done {
+		t.Error("undoing left the intention done")
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	d := newDriver(t)
+	yesterday := d.today.AddDate(0, 0, -1)
+	d.add(yesterday, "w) draft", "h) swim")
+	draft := d.intention(yesterday, "w) draft")
+	draft.Done, draft.Pomos = true, 2
+	if err := d.store.UpsertIntentions([]data.Intention{draft}); err != nil {
+		t.Fatal(err)
+	}
+	work := draft.Whys[0]
+	days := []data.Day{{Date: yesterday, WhyID: work.ID, Enough: true, Reflection: "went well"}}
+	if err := d.store.UpsertDayReview(days); err != nil {
+		t.Fatal(err)
+	}
+
+	d.press("f3")
+	d.shows(yesterday.Format("Monday 2, January 2006")+"  1/2 done, 2 pomos",
+		"[✓] w) draft 🍅🍅", "[ ] h) swim", "Work  enough ✓", "went well")
+	// days before any were recorded are shown as such
+	d.shows("nothing recorded")
+
+	d.press("j")
+	d.hides(d.today.Format("Monday 2, January 2006"))
+	d.press("k")
+	d.shows(d.today.Format("Monday 2, January 2006"))
+}
+
+func TestReviewPeriods(t *testing.T) {
+	d := newDriver(t)
+	work := d.intention(d.today, "w) write").Whys[0]
+	// a week which is always within the current month
+	month, _ := data.Monthly.Bounds(d.today)
+	start, end := data.Weekly.Bounds(month.AddDate(0, 0, 7))
+	review := data.Review{Period: data.Weekly, Start: start, End: end, WhyID: work.ID, Progress: "drafted it all"}
+	if err := d.store.UpsertReviews([]data.Review{review}); err != nil {
+		t.Fatal(err)
+	}
+
+	d.press("f4")
+	d.shows("Weekly review:", "no reflections this week")
+	// the answers for the weeks of a month are shown in its review
+	d.press("p")
+	d.shows("Monthly review: "+d.today.Format("January 2006"),
+		"Weekly review: "+start.Format("Mon Jan 2"), "What progress did you make? drafted it all")
+	d.press("p")
+	d.shows("Quarterly review: Q", "no monthly reviews in this period")
+	d.press("p")
+	d.shows("Yearly review: " + d.today.Format("2006"))
+	d.press("p")
+	d.shows("Weekly review:")
+}
+
+func TestDefaultKeysDontConflict(t *testing.T) {
+	d := newDriver(t)
+	if problems := d.m.(Model).Keys.Problems(); len(problems) != 0 {
+		t.Errorf("the default keys have problems:\n%s", strings.Join(problems, "\n"))
+	}
+}
+
+func TestRemappedKeys(t *testing.T) {
+	cfg := config.Default()
+	cfg.Keys = map[string]map[string][]string{"today": {"mark_done": {"x"}}}
+	d := newDriverWith(t, cfg)
+	d.press("space")
+	if d.intention(d.today, "w) write").Done {
+		t.Error("space still marks intentions done once mark_done is remapped to x")
+	}
+	d.press("x")
+	if !d.intention(d.today, "w) write").Done {
+		t.Error("x doesn't mark intentions done once mark_done is remapped to it")
+	}
+}
+
+func TestPomodoroInterrupted(t *testing.T) {
+	d := newDriver(t)
+	d.press("t")
+	d.shows("🍅 25:00  w) write")
+	d.press("t")
+	write := d.intention(d.today, "w) write")
+	if write.Pomos != 0 || write.Interruptions != 1 {
+		t.Errorf("got %d pomos and %d interruptions, want an interruption alone", write.Pomos, write.Interruptions)
+	}
+	sessions, err := d.store.GetPomoSessionsBetween(d.today, d.today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Completed || sessions[0].IntentionID != write.ID {
+		t.Fatalf("got sessions %+v, want one interrupted on w) write", sessions)
+	}
+	// the day's sessions are listed in the timeline
+	d.press("f3")
+	d.shows("✗ " + sessions[0].Start.Format("15:04") + "–" + sessions[0].End.Format("15:04") + " w) write")
+}
+
+func TestCarryOver(t *testing.T) {
+	d := newDriver(t)
+	d.add(d.today, "w) edit")
+	write, edit := d.intention(d.today, "w) write"), d.intention(d.today, "w) edit")
+	write.Done, edit.Position = true, 2
+	if err := d.store.UpsertIntentions([]data.Intention{write, edit}); err != nil {
+		t.Fatal(err)
+	}
+	d.review(d.today)
+	run := d.intention(d.today, "h) run")
+
+	// once the day's outcomes are given, the next one is begun with what
+	// wasn't finished offered to carry over
+	d.run(d.m.Init())
+	d.shows("Carry over unfinished intentions?", "h) run", "w) edit")
+	d.press("tab", "j", "space", "tab", "ctrl+d")
+
+	tomorrow := d.today.AddDate(0, 0, 1)
+	carried, err := d.store.GetDaysIntentions(tomorrow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(carried) != 1 || carried[0].Content != "h) run" || carried[0].CarriedFromID == nil ||
+		*carried[0].CarriedFromID != run.ID || len(carried[0].Whys) != 1 {
+		t.Fatalf("got %+v, want h) run alone carried over from %d", carried, run.ID)
+	}
+	if got := d.intention(d.today, "w) edit"); got.ID != edit.ID {
+		t.Errorf("w) edit was changed to %+v by not carrying it over", got)
+	}
+	d.shows("1 intentions for today", "h) run")
+}
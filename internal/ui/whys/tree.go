@@ -0,0 +1,109 @@
+package whys
+
+import (
+	"github.com/benhsm/goalie/internal/data"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Goals are listed depth first, each followed by its sub-goals, so that a
+// goal and everything under it is a run of the list which can be moved as
+// one.
+
+// depth returns how many levels the goal at i is below the top, counting
+// only the parents which are in whys
+func depth(whys []data.Why, i int) int {
+	parents := make(map[uint]*uint)
+	for _, why := range whys {
+		if why.ID != 0 {
+			parents[why.ID] = why.ParentID
+		}
+	}
+	d := 0
+	parent := whys[i].ParentID
+	for parent != nil && d < len(whys) {
+		next, ok := parents[*parent]
+		if !ok {
+			break
+		}
+		parent = next
+		d++
+	}
+	return d
+}
+
+// subtreeEnd returns the index after the last goal under the one at i
+func subtreeEnd(whys []data.Why, i int) int {
+	d := depth(whys, i)
+	end := i + 1
+	for end < len(whys) && depth(whys, end) > d {
+		end++
+	}
+	return end
+}
+
+// prevSibling returns the index of the goal before the one at i with the
+// same parent, or -1 if there is none
+func prevSibling(whys []data.Why, i int) int {
+	d := depth(whys, i)
+	for j := i - 1; j >= 0; j-- {
+		switch dj := depth(whys, j); {
+		case dj < d:
+			return -1
+		case dj == d:
+			return j
+		}
+	}
+	return -1
+}
+
+// parentIndex returns the index of the parent of the goal at i, or -1 if it
+// has none in whys
+func parentIndex(whys []data.Why, i int) int {
+	if whys[i].ParentID == nil {
+		return -1
+	}
+	return indexOf(whys, *whys[i].ParentID)
+}
+
+// indexOf returns the index of the goal with id, or -1 if it isn't in whys
+func indexOf(whys []data.Why, id uint) int {
+	for i, why := range whys {
+		if why.ID != 0 && why.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// adopt moves the sub-goals of the goal at i up to its parent
+func adopt(whys []data.Why, i int) {
+	for j := i + 1; j < len(whys); j++ {
+		if whys[j].ParentID != nil && whys[i].ID != 0 && *whys[j].ParentID == whys[i].ID {
+			whys[j].ParentID = whys[i].ParentID
+		}
+	}
+}
+
+// move returns whys with the goals from start up to end moved to before the
+// goal at to, which must not be between them
+func move(whys []data.Why, start, end, to int) []data.Why {
+	block := append([]data.Why{}, whys[start:end]...)
+	rest := append(append([]data.Why{}, whys[:start]...), whys[end:]...)
+	if to > start {
+		to -= end - start
+	}
+	result := append(append([]data.Why{}, rest[:to]...), block...)
+	return append(result, rest[to:]...)
+}
+
+// setColor changes the color of the goal at i, along with those of its
+// sub-goals which had inherited its old color
+func setColor(whys []data.Why, i int, color lipgloss.Color) {
+	old := whys[i].Color
+	for j := i + 1; j < subtreeEnd(whys, i); j++ {
+		if whys[j].Color == old {
+			whys[j].Color = color
+		}
+	}
+	whys[i].Color = color
+}
@@ -3,10 +3,13 @@
 package whys
 
 import (
+	"errors"
+	"fmt"
 	"math/rand"
 	"strings"
 	"time"
 
+	"github.com/benhsm/goalie/internal/data"
 	"github.com/benhsm/goalie/internal/ui/common"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -38,6 +41,10 @@ var (
 type goalInputModel struct {
 	common.Common
 	TitleInput    textinput.Model
+	CodeInput     textinput.Model
+	StartInput    textinput.Model
+	DeadlineInput textinput.Model
+	TargetInput   textinput.Model
 	DescInput     textarea.Model
 	focusIndex    int
 	colorpicker   colorPickerModel
@@ -45,6 +52,9 @@ type goalInputModel struct {
 	Cancelled     bool
 	Color         lipgloss.Color
 	choosingColor bool
+	// codes are those of the other goals, which the goal's must differ from
+	codes []string
+	err   string
 
 	keys inputKeyMap
 	help help.Model
@@ -52,18 +62,38 @@ type goalInputModel struct {
 
 const (
 	focusTitle = iota
+	focusCode
+	focusStart
+	focusDeadline
+	focusTarget
 	focusDesc
 	focusColor
 	focusDone
 	focusCancel
 )
 
-// New returns a New goalinput model
-func newGoalInput() goalInputModel {
+// New returns a New goalinput model for a goal whose code must differ from
+// codes
+func newGoalInput(keys inputKeyMap, codes []string) goalInputModel {
 	ti := textinput.New()
 	ti.Placeholder = "goal title"
 	ti.CharLimit = 50
 	ti.Focus()
+	ci := textinput.New()
+	ci.Prompt = "code: "
+	ci.Placeholder = "letters or digits"
+	ci.CharLimit = data.MaxCodeLength
+	si := textinput.New()
+	si.Prompt = "start date: "
+	si.Placeholder = "YYYY-MM-DD, optional"
+	si.CharLimit = len(dateLayout)
+	di := textinput.New()
+	di.Prompt = "target date: "
+	di.Placeholder = "YYYY-MM-DD, optional"
+	di.CharLimit = len(dateLayout)
+	tgi := textinput.New()
+	tgi.Prompt = "target: "
+	tgi.Placeholder = "e.g. 50 pomos or 20 done, optional"
 	ta := textarea.New()
 	ta.Placeholder = "goal description"
 
@@ -71,12 +101,17 @@ func newGoalInput() goalInputModel {
 	cp := newColorPicker()
 	randomIndex := rand.Intn(len(cp.Colors))
 	return goalInputModel{
-		TitleInput:  ti,
-		DescInput:   ta,
-		colorpicker: cp,
-		Color:       cp.Colors[randomIndex],
-		help:        help.New(),
-		keys:        inputKeys,
+		TitleInput:    ti,
+		CodeInput:     ci,
+		StartInput:    si,
+		DeadlineInput: di,
+		TargetInput:   tgi,
+		DescInput:     ta,
+		codes:         codes,
+		colorpicker:   cp,
+		Color:         cp.Colors[randomIndex],
+		help:          help.New(),
+		keys:          keys,
 	}
 }
 
@@ -94,10 +129,14 @@ func (m goalInputModel) View() string {
 	}
 
 	titleInput := titleInputStyle.Render(m.TitleInput.View())
+	codeInput := titleInputStyle.Render(m.CodeInput.View())
+	startInput := titleInputStyle.Render(m.StartInput.View())
+	deadlineInput := titleInputStyle.Render(m.DeadlineInput.View())
+	targetInput := titleInputStyle.Render(m.TargetInput.View())
 
 	descInput := descInputStyle.Render(m.DescInput.View())
 
-	inputFields := lipgloss.JoinVertical(lipgloss.Left, titleInput, descInput)
+	inputFields := lipgloss.JoinVertical(lipgloss.Left, titleInput, codeInput, startInput, deadlineInput, targetInput, descInput)
 
 	var colorButton, colorDisplay string
 	colorDisplay = lipgloss.NewStyle().Background(m.Color).Foreground(lipgloss.Color("#FFFFFF")).Render(string(m.Color))
@@ -124,7 +163,7 @@ func (m goalInputModel) View() string {
 
 	buttons := lipgloss.JoinHorizontal(lipgloss.Center, doneButton, cancelButton)
 
-	b.WriteString(lipgloss.JoinVertical(lipgloss.Center, inputFields, colorField, buttons, "", m.help.View(inputKeys)))
+	b.WriteString(lipgloss.JoinVertical(lipgloss.Center, inputFields, colorField, buttons, m.err, m.help.View(m.keys)))
 
 	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center,
 		b.String())
@@ -167,7 +206,12 @@ func (m goalInputModel) goalInputUpdate(msg tea.Msg) (goalInputModel, tea.Cmd) {
 
 			if key.Matches(msg, m.keys.Select) {
 				if m.focusIndex == focusDone {
-					m.Done = true
+					if focus, err := m.check(); err != nil {
+						m.err = err.Error()
+						m.focusIndex = focus
+					} else {
+						m.Done = true
+					}
 				} else if m.focusIndex == focusCancel {
 					m.Done = true
 					m.Cancelled = true
@@ -191,6 +235,30 @@ func (m goalInputModel) goalInputUpdate(msg tea.Msg) (goalInputModel, tea.Cmd) {
 				m.TitleInput.Blur()
 			}
 
+			if m.focusIndex == focusCode {
+				cmds = append(cmds, m.CodeInput.Focus())
+			} else {
+				m.CodeInput.Blur()
+			}
+
+			if m.focusIndex == focusStart {
+				cmds = append(cmds, m.StartInput.Focus())
+			} else {
+				m.StartInput.Blur()
+			}
+
+			if m.focusIndex == focusDeadline {
+				cmds = append(cmds, m.DeadlineInput.Focus())
+			} else {
+				m.DeadlineInput.Blur()
+			}
+
+			if m.focusIndex == focusTarget {
+				cmds = append(cmds, m.TargetInput.Focus())
+			} else {
+				m.TargetInput.Blur()
+			}
+
 			if m.focusIndex == focusDesc {
 				cmds = append(cmds, m.DescInput.Focus())
 			} else {
@@ -204,12 +272,96 @@ func (m goalInputModel) goalInputUpdate(msg tea.Msg) (goalInputModel, tea.Cmd) {
 	m.TitleInput, cmd = m.TitleInput.Update(msg)
 	cmds = append(cmds, cmd)
 
+	m.CodeInput, cmd = m.CodeInput.Update(msg)
+	cmds = append(cmds, cmd)
+
+	m.StartInput, cmd = m.StartInput.Update(msg)
+	cmds = append(cmds, cmd)
+
+	m.DeadlineInput, cmd = m.DeadlineInput.Update(msg)
+	cmds = append(cmds, cmd)
+
+	m.TargetInput, cmd = m.TargetInput.Update(msg)
+	cmds = append(cmds, cmd)
+
 	m.DescInput, cmd = m.DescInput.Update(msg)
 	cmds = append(cmds, cmd)
 
 	return m, tea.Batch(cmds...)
 }
 
+// dateLayout is the format in which start and target dates are entered
+const dateLayout = "2006-01-02"
+
+// check returns an error for the first field which is invalid, along with
+// its focus index. The code must differ from every other goal's.
+func (m goalInputModel) check() (int, error) {
+	code := m.CodeInput.Value()
+	if err := data.ValidCode(code); err != nil {
+		return focusCode, err
+	}
+	for _, c := range m.codes {
+		if strings.EqualFold(c, code) {
+			return focusCode, fmt.Errorf("another goal has the code %s", c)
+		}
+	}
+	start, err := parseOptionalDate(m.StartInput.Value())
+	if err != nil {
+		return focusStart, err
+	}
+	deadline, err := parseOptionalDate(m.DeadlineInput.Value())
+	if err != nil {
+		return focusDeadline, err
+	}
+	if start != nil && deadline != nil && deadline.Before(*start) {
+		return focusDeadline, errors.New("the target date is before the start date")
+	}
+	if _, _, err := data.ParseTarget(m.TargetInput.Value()); err != nil {
+		return focusTarget, err
+	}
+	return 0, nil
+}
+
+// fill sets the fields to those of why, apart from its color
+func (m *goalInputModel) fill(why data.Why) {
+	m.TitleInput.SetValue(why.Name)
+	m.CodeInput.SetValue(why.Code)
+	m.DescInput.SetValue(why.Description)
+	m.StartInput.SetValue(formatOptionalDate(why.StartDate))
+	m.DeadlineInput.SetValue(formatOptionalDate(why.TargetDate))
+	m.TargetInput.SetValue(data.FormatTarget(why))
+}
+
+// apply sets why's fields, apart from its color, to those entered, which
+// check must have accepted
+func (m goalInputModel) apply(why *data.Why) {
+	why.Name = m.TitleInput.Value()
+	why.Code = m.CodeInput.Value()
+	why.Description = m.DescInput.Value()
+	why.StartDate, _ = parseOptionalDate(m.StartInput.Value())
+	why.TargetDate, _ = parseOptionalDate(m.DeadlineInput.Value())
+	why.Target, why.Measure, _ = data.ParseTarget(m.TargetInput.Value())
+}
+
+func parseOptionalDate(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation(dateLayout, s, time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return &date, nil
+}
+
+func formatOptionalDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(dateLayout)
+}
+
 type inputKeyMap struct {
 	Done            key.Binding
 	Quit            key.Binding
@@ -219,7 +371,8 @@ type inputKeyMap struct {
 	Help            key.Binding
 }
 
-var inputKeys = inputKeyMap{
+// defaultInputKeys are the bindings used unless the user configures others
+var defaultInputKeys = inputKeyMap{
 	Done: key.NewBinding(
 		key.WithKeys("ctrl+d"),
 		key.WithHelp("ctrl+d", "submit"),
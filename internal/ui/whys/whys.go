@@ -1,7 +1,7 @@
 package whys
 
 import (
-	"strconv"
+	"fmt"
 	"strings"
 
 	"github.com/benhsm/goalie/internal/data"
@@ -39,12 +39,28 @@ var (
 	selectedlistItemStyle = listItemStyle.Copy().
 				Border(lipgloss.NormalBorder(), false, false, false, true).
 				Padding(0, 0, 0, 0)
+	targetStyle = func(color lipgloss.Color) lipgloss.Style {
+		return lipgloss.NewStyle().
+			Foreground(color).
+			Width(80).
+			Margin(0, 0, 0, 1).
+			Padding(0, 0, 0, 1)
+	}
+	overdueStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF5F5F")).
+			Bold(true).
+			Width(80).
+			Margin(0, 0, 0, 1).
+			Padding(0, 0, 0, 1)
 	prefixStyle = func(color lipgloss.Color) lipgloss.Style {
 		return lipgloss.NewStyle().
 			Foreground(lipgloss.Color(color))
 	}
 )
 
+// progressWidth is the width of the bars showing progress towards targets
+const progressWidth = 30
+
 type iostateEnum int
 
 const (
@@ -54,31 +70,151 @@ const (
 )
 
 type Model struct {
-	common       common.Common
-	whys         []data.Why
-	focusIndex   int
-	input        goalInputModel
-	editing      bool
-	adding       bool
+	common common.Common
+	whys   []data.Why
+	// archived goals are kept with their history but hidden from the other
+	// pages. They are listed instead of the active goals while showArchived.
+	archived     []data.Why
+	showArchived bool
+	// progress is what has been done towards each goal's target, by ID
+	progress   map[uint]data.Progress
+	focusIndex int
+	input      goalInputModel
+	editing    bool
+	adding     bool
+	// addingTo is the index of the goal a sub-goal is being added to, or -1
+	// when adding a top-level goal
+	addingTo int
+	// deciding is set while asking whether the sub-goals of the goal being
+	// archived or deleted go with it
+	deciding     bool
 	iostate      iostateEnum
 	errMessage   string
 	whysToDelete []data.Why
 	height       int
 	width        int
 	keys         keyMap
+	inputKeys    inputKeyMap
 	help         help.Model
 }
 
 func New(c common.Common) *Model {
-	return &Model{
-		common: c,
-		keys:   keys,
-		help:   help.New(),
+	m := &Model{
+		common:    c,
+		keys:      defaultKeys,
+		inputKeys: defaultInputKeys,
+		help:      help.New(),
 	}
+	c.Keys.Register("goals", &m.keys)
+	c.Keys.Register("goal_input", &m.inputKeys)
+	m.setShowArchived(false)
+	m.setDeciding(false)
+	return m
 }
 
 func (m *Model) Init() tea.Cmd {
-	return m.common.ReadWhys(data.Active)
+	return m.read()
+}
+
+// read requests both the active and the archived goals, and their progress,
+// discarding any unsynced changes
+func (m *Model) read() tea.Cmd {
+	m.whysToDelete = nil
+	return tea.Batch(m.common.ReadWhys(data.Active), m.common.ReadWhys(data.Archived), m.common.GetProgress())
+}
+
+// Typing reports whether a goal is being edited, or what to do with its
+// sub-goals is being asked
+func (m *Model) Typing() bool {
+	return m.editing || m.deciding
+}
+
+// list returns the goals being shown
+func (m *Model) list() []data.Why {
+	if m.showArchived {
+		return m.archived
+	}
+	return m.whys
+}
+
+// setShowArchived switches between the active and archived goals. Archived
+// goals can't be edited or reordered, but they are the only ones which can
+// be deleted, along with their history.
+func (m *Model) setShowArchived(show bool) {
+	m.showArchived = show
+	m.focusIndex = 0
+	for _, k := range []*key.Binding{&m.keys.ShiftUp, &m.keys.ShiftDown, &m.keys.Edit, &m.keys.Add,
+		&m.keys.AddSub, &m.keys.Indent, &m.keys.Outdent} {
+		k.SetEnabled(!show)
+	}
+	m.keys.Delete.SetEnabled(show)
+	if show {
+		m.keys.Archive.SetHelp(m.keys.Archive.Help().Key, "unarchive item")
+		m.keys.ShowArchived.SetHelp(m.keys.ShowArchived.Help().Key, "show active")
+	} else {
+		m.keys.Archive.SetHelp(m.keys.Archive.Help().Key, "archive item")
+		m.keys.ShowArchived.SetHelp(m.keys.ShowArchived.Help().Key, "show archived")
+	}
+}
+
+// setDeciding enables only the keys answering what happens to the sub-goals
+// of the focused goal while deciding
+func (m *Model) setDeciding(deciding bool) {
+	m.deciding = deciding
+	for _, k := range []*key.Binding{&m.keys.WithSubGoals, &m.keys.KeepSubGoals, &m.keys.Cancel} {
+		k.SetEnabled(deciding)
+	}
+}
+
+// archive archives the goal at i, with its sub-goals or moving them up a
+// level
+func (m *Model) archive(i int, withSubGoals bool) {
+	end := i + 1
+	if withSubGoals {
+		end = subtreeEnd(m.whys, i)
+	} else {
+		adopt(m.whys, i)
+	}
+	for j := i; j < end; j++ {
+		m.whys[j].Archived = true
+	}
+	m.archived = append(m.archived, m.whys[i:end]...)
+	m.whys = append(append([]data.Why{}, m.whys[:i]...), m.whys[end:]...)
+}
+
+// unarchive restores the archived goal at i along with its archived
+// sub-goals. It goes back under its parent if that is active, and to the top
+// level otherwise.
+func (m *Model) unarchive(i int) {
+	end := subtreeEnd(m.archived, i)
+	restored := append([]data.Why{}, m.archived[i:end]...)
+	for j := range restored {
+		restored[j].Archived = false
+	}
+	m.archived = append(append([]data.Why{}, m.archived[:i]...), m.archived[end:]...)
+
+	to := len(m.whys)
+	if restored[0].ParentID != nil {
+		if p := indexOf(m.whys, *restored[0].ParentID); p >= 0 {
+			to = subtreeEnd(m.whys, p)
+		} else {
+			restored[0].ParentID = nil
+		}
+	}
+	m.whys = append(append(append([]data.Why{}, m.whys[:to]...), restored...), m.whys[to:]...)
+}
+
+// delete marks the archived goal at i to be deleted, with its sub-goals or
+// moving them up a level
+func (m *Model) delete(i int, withSubGoals bool) {
+	end := i + 1
+	if withSubGoals {
+		end = subtreeEnd(m.archived, i)
+	} else {
+		adopt(m.archived, i)
+	}
+	m.whysToDelete = append(m.whysToDelete, m.archived[i:end]...)
+	m.archived = append(append([]data.Why{}, m.archived[:i]...), m.archived[end:]...)
 }
 
 func (m *Model) View() string {
@@ -87,17 +223,34 @@ func (m *Model) View() string {
 	if m.editing {
 		return m.input.View()
 	} else {
-		for i, g := range m.whys {
-			listItem := m.WhyRender(g, strconv.Itoa(i))
+		if m.showArchived {
+			b.WriteString("Archived goals\n\n")
+			if len(m.archived) == 0 {
+				b.WriteString("There are no archived goals.\n\n")
+			}
+		}
+		for i, g := range m.list() {
+			listItem := m.WhyRender(g, g.Code)
+			// sub-goals are indented under their parents
+			indent := depth(m.list(), i) * 4
 			if i == m.focusIndex {
-				b.WriteString(selectedlistItemStyle.
+				b.WriteString(selectedlistItemStyle.Copy().MarginLeft(indent).
 					Render(listItem))
 			} else {
-				b.WriteString(listItemStyle.
+				b.WriteString(listItemStyle.Copy().MarginLeft(indent).
 					Render(listItem))
 			}
 			b.WriteString("\n\n")
 		}
+		if m.deciding {
+			action := "Archive"
+			if m.showArchived {
+				action = "Delete"
+			}
+			fmt.Fprintf(&b, "%s has sub-goals.\n%s them too (%s), or move them up a level (%s)? %s cancels\n",
+				m.list()[m.focusIndex].Name, action, m.keys.WithSubGoals.Help().Key,
+				m.keys.KeepSubGoals.Help().Key, m.keys.Cancel.Help().Key)
+		}
 		switch m.iostate {
 		case synced:
 			b.WriteString("changes synced to database\n")
@@ -114,16 +267,65 @@ func (m *Model) View() string {
 
 func (m *Model) WhyRender(w data.Why, prefix string) string {
 	m.common.FigletOpts.FontName = "future"
-	// TODO: This won't work with non-ascii prefixes
+	// codes are ascii, which the font has letters for
 	bigPrefix, _ := m.common.Figlet.RenderOpts(prefix, m.common.FigletOpts)
 	bigPrefix = strings.TrimRight(bigPrefix, "\n")
-	title := titleStyle(w.Color).Render(w.Name)
+	name := w.Name
+	target, overdue := m.targetLine(w)
+	if overdue {
+		name += " (overdue)"
+	}
+	title := titleStyle(w.Color).Render(name)
 	desc := descriptionStyle(w.Color).Render(w.Description)
 	contents := lipgloss.JoinVertical(lipgloss.Left, title, desc)
+	if target != "" {
+		style := targetStyle(w.Color)
+		if overdue {
+			style = overdueStyle
+		}
+		contents = lipgloss.JoinVertical(lipgloss.Left, contents, style.Render(target))
+	}
 	result := lipgloss.JoinHorizontal(lipgloss.Center, prefixStyle(w.Color).Render(bigPrefix), contents)
 	return result
 }
 
+// targetLine describes the goal's progress towards its target and the days
+// left until its target date, or is "" if it has neither. overdue is true for
+// active goals whose target date has passed.
+func (m *Model) targetLine(w data.Why) (line string, overdue bool) {
+	var parts []string
+	if w.Target > 0 {
+		amount := m.progress[w.ID].Amount(w.Measure)
+		filled := progressWidth
+		if amount < w.Target {
+			filled = progressWidth * amount / w.Target
+		}
+		parts = append(parts, fmt.Sprintf("%s%s %d/%d %s",
+			strings.Repeat("█", filled), strings.Repeat("░", progressWidth-filled),
+			amount, w.Target, w.Measure))
+		if amount >= w.Target {
+			parts = append(parts, "reached")
+		}
+	}
+	if days, ok := data.DaysLeft(w, m.common.CurrentDay()); ok {
+		switch {
+		case days > 1:
+			parts = append(parts, fmt.Sprintf("%d days left", days))
+		case days == 1:
+			parts = append(parts, "1 day left")
+		case days == 0:
+			parts = append(parts, "due today")
+		case w.Archived:
+			parts = append(parts, "target date passed")
+		default:
+			overdue = true
+			parts = append(parts, fmt.Sprintf("target date passed %s, %s to archive",
+				w.TargetDate.Format("Jan 2"), m.keys.Archive.Help().Key))
+		}
+	}
+	return strings.Join(parts, " · "), overdue
+}
+
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	var cmd tea.Cmd
@@ -135,16 +337,20 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if !m.input.Cancelled {
 				m.iostate = unsynced
 				if m.adding {
-					newGoal := data.Why{
-						Name:        m.input.TitleInput.Value(),
-						Description: m.input.DescInput.Value(),
-						Color:       m.input.Color,
+					newGoal := data.Why{Color: m.input.Color}
+					m.input.apply(&newGoal)
+					if m.addingTo >= 0 {
+						// sub-goals are added after the parent's others
+						id := m.whys[m.addingTo].ID
+						newGoal.ParentID = &id
+						to := subtreeEnd(m.whys, m.addingTo)
+						m.whys = append(m.whys[:to], append([]data.Why{newGoal}, m.whys[to:]...)...)
+					} else {
+						m.whys = append(m.whys, newGoal)
 					}
-					m.whys = append(m.whys, newGoal)
 				} else {
-					m.whys[m.focusIndex].Name = m.input.TitleInput.Value()
-					m.whys[m.focusIndex].Description = m.input.DescInput.Value()
-					m.whys[m.focusIndex].Color = m.input.Color
+					m.input.apply(&m.whys[m.focusIndex])
+					setColor(m.whys, m.focusIndex, m.input.Color)
 				}
 			}
 			m.adding = false
@@ -152,23 +358,63 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, cmd
 	} else {
+		if msg, ok := msg.(tea.KeyMsg); ok && m.deciding {
+			withSubGoals := key.Matches(msg, m.keys.WithSubGoals)
+			if withSubGoals || key.Matches(msg, m.keys.KeepSubGoals) {
+				if m.showArchived {
+					m.delete(m.focusIndex, withSubGoals)
+				} else {
+					m.archive(m.focusIndex, withSubGoals)
+				}
+				m.iostate = unsynced
+			} else if !key.Matches(msg, m.keys.Cancel) {
+				return m, nil
+			}
+			m.setDeciding(false)
+			if m.focusIndex > len(m.list())-1 {
+				m.focusIndex = len(m.list()) - 1
+			}
+			return m, nil
+		}
+
 		switch msg := msg.(type) {
 		case common.ErrMsg:
 			if msg.Error != nil {
 				m.errMessage = msg.Error.Error()
-			} else {
-				return m, m.common.ReadWhys(data.All)
+			} else if m.iostate != unsynced {
+				// reading again would lose changes made since
+				return m, m.read()
+			}
+		case common.ProgressMsg:
+			if msg.Error != nil {
+				m.errMessage = msg.Error.Error()
+			}
+			m.progress = msg.Data
+		case common.UndoMsg:
+			m.errMessage = msg.String()
+			if msg.Error == nil {
+				if m.iostate == unsynced {
+					m.errMessage += fmt.Sprintf(", %s to discard your changes and reload",
+						m.keys.Reload.Help().Key)
+				} else {
+					return m, m.read()
+				}
 			}
 		case common.WhyDataMsg:
 			if msg.Error != nil {
 				m.errMessage = msg.Error.Error()
 			}
-			m.whys = msg.Data
+			if msg.Status == data.Archived {
+				m.archived = msg.Data
+			} else {
+				m.whys = msg.Data
+			}
 			m.iostate = synced
 		case tea.WindowSizeMsg:
 			m.SetSize(msg.Height, msg.Width)
 			//			m.help.Width = msg.Width
 		case tea.KeyMsg:
+			m.errMessage = ""
 			switch {
 			case key.Matches(msg, m.keys.Quit):
 				return m, tea.Quit
@@ -178,56 +424,136 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.focusIndex--
 			case key.Matches(msg, m.keys.Down):
 				m.focusIndex++
-			case key.Matches(msg, m.keys.ShiftUp):
-				if m.focusIndex > 0 {
-					m.whys[m.focusIndex-1], m.whys[m.focusIndex] = m.whys[m.focusIndex], m.whys[m.focusIndex-1]
-					m.focusIndex--
+			// goals are moved along with their sub-goals, past the goals
+			// with the same parent
+			case len(m.whys) > 0 && key.Matches(msg, m.keys.ShiftUp):
+				if p := prevSibling(m.whys, m.focusIndex); p >= 0 {
+					m.whys = move(m.whys, m.focusIndex, subtreeEnd(m.whys, m.focusIndex), p)
+					m.focusIndex = p
+				}
+				m.iostate = unsynced
+			case len(m.whys) > 0 && key.Matches(msg, m.keys.ShiftDown):
+				end := subtreeEnd(m.whys, m.focusIndex)
+				if end < len(m.whys) && depth(m.whys, end) == depth(m.whys, m.focusIndex) {
+					next := subtreeEnd(m.whys, end)
+					m.whys = move(m.whys, end, next, m.focusIndex)
+					m.focusIndex += next - end
 				}
 				m.iostate = unsynced
-			case key.Matches(msg, m.keys.ShiftDown):
-				if m.focusIndex < len(m.whys)-1 {
-					m.whys[m.focusIndex+1], m.whys[m.focusIndex] = m.whys[m.focusIndex], m.whys[m.focusIndex+1]
-					m.focusIndex++
+			case len(m.whys) > 0 && key.Matches(msg, m.keys.Indent):
+				// the goal becomes a sub-goal of the one above it
+				p := prevSibling(m.whys, m.focusIndex)
+				if p < 0 {
+					break
 				}
+				if m.whys[p].ID == 0 {
+					m.errMessage = "sync new goals before adding sub-goals to them"
+					break
+				}
+				id := m.whys[p].ID
+				m.whys[m.focusIndex].ParentID = &id
 				m.iostate = unsynced
-			case key.Matches(msg, m.keys.Delete):
-				m.whysToDelete = append(m.whysToDelete, m.whys[m.focusIndex])
-				m.whys = removeItemFromSlice(m.whys, m.focusIndex)
+			case len(m.whys) > 0 && key.Matches(msg, m.keys.Outdent):
+				// the goal is moved after its parent's other sub-goals
+				q := parentIndex(m.whys, m.focusIndex)
+				if q < 0 {
+					break
+				}
+				end := subtreeEnd(m.whys, m.focusIndex)
+				to := subtreeEnd(m.whys, q)
+				m.whys[m.focusIndex].ParentID = m.whys[q].ParentID
+				m.whys = move(m.whys, m.focusIndex, end, to)
+				m.focusIndex = to - (end - m.focusIndex)
 				m.iostate = unsynced
-			case key.Matches(msg, m.keys.Add, m.keys.Edit):
+			case key.Matches(msg, m.keys.ShowArchived):
+				m.setShowArchived(!m.showArchived)
+			case len(m.list()) > 0 && key.Matches(msg, m.keys.Archive):
+				// archived goals are moved to the end of the active ones
+				// when they are restored
+				if m.showArchived {
+					m.unarchive(m.focusIndex)
+				} else if subtreeEnd(m.whys, m.focusIndex) > m.focusIndex+1 {
+					m.setDeciding(true)
+					break
+				} else {
+					m.archive(m.focusIndex, false)
+				}
+				m.iostate = unsynced
+			case len(m.archived) > 0 && key.Matches(msg, m.keys.Delete):
+				if subtreeEnd(m.archived, m.focusIndex) > m.focusIndex+1 {
+					m.setDeciding(true)
+					break
+				}
+				m.delete(m.focusIndex, false)
+				m.iostate = unsynced
+			case key.Matches(msg, m.keys.Add, m.keys.Edit, m.keys.AddSub):
+				editing := key.Matches(msg, m.keys.Edit)
+				m.addingTo = -1
+				if key.Matches(msg, m.keys.AddSub) {
+					if len(m.whys) == 0 {
+						break
+					}
+					if m.whys[m.focusIndex].ID == 0 {
+						m.errMessage = "sync new goals before adding sub-goals to them"
+						break
+					}
+					m.addingTo = m.focusIndex
+				}
+				// goals waiting to be deleted keep their codes until then
+				var others []data.Why
+				for i, why := range m.whys {
+					if !(editing && i == m.focusIndex) {
+						others = append(others, why)
+					}
+				}
+				others = append(append(others, m.archived...), m.whysToDelete...)
+				var codes []string
+				for _, why := range others {
+					codes = append(codes, why.Code)
+				}
 				m.editing = true
-				m.input = newGoalInput()
+				m.input = newGoalInput(m.inputKeys, codes)
 				m.input.SetSize(m.height, m.width)
 				initCmd := m.input.Init()
-				if key.Matches(msg, m.keys.Edit) {
-					m.input.TitleInput.SetValue(m.whys[m.focusIndex].Name)
-					m.input.DescInput.SetValue(m.whys[m.focusIndex].Description)
+				if editing {
+					m.input.fill(m.whys[m.focusIndex])
 					m.input.Color = m.whys[m.focusIndex].Color
 				} else {
+					m.input.CodeInput.SetValue(data.NextCode(others))
+					if m.addingTo >= 0 {
+						// sub-goals start with their parent's color
+						m.input.Color = m.whys[m.addingTo].Color
+					}
 					m.adding = true
 				}
 				return m, initCmd
 			case key.Matches(msg, m.keys.Sync):
 				if m.iostate == unsynced {
+					// archived goals are numbered after the active ones so
+					// that they are listed in the same order when reloaded
 					for i := range m.whys {
 						m.whys[i].Number = i
 					}
-					cmd = m.common.UpsertWhys(m.whys)
-					cmds = append(cmds, cmd)
-					cmd = m.common.DeleteWhys(m.whysToDelete)
+					for i := range m.archived {
+						m.archived[i].Number = len(m.whys) + i
+					}
+					changed := append(append([]data.Why{}, m.whys...), m.archived...)
+					// saved as one change so that a sync is undone at once
+					cmd = m.common.SyncWhys(changed, m.whysToDelete)
 					cmds = append(cmds, cmd)
+					m.whysToDelete = nil
 					m.iostate = syncing
 				}
 			case key.Matches(msg, m.keys.Reload):
-				return m, m.common.ReadWhys(data.All)
+				return m, m.read()
 			}
 		}
 
-		if m.focusIndex > len(m.whys)-1 {
+		if m.focusIndex > len(m.list())-1 {
 			m.focusIndex = 0
 		}
 		if m.focusIndex < 0 {
-			m.focusIndex = len(m.whys) - 1
+			m.focusIndex = len(m.list()) - 1
 		}
 
 		return m, tea.Batch(cmds...)
@@ -239,16 +565,6 @@ func (m *Model) SetSize(height, width int) {
 	m.width = width
 }
 
-// Remove an item from a slice of items at the given index. This runs in O(n).
-func removeItemFromSlice(i []data.Why, index int) []data.Why {
-	if index >= len(i) {
-		return i // noop
-	}
-	copy(i[index:], i[index+1:])
-	i[len(i)-1] = data.Why{}
-	return i[:len(i)-1]
-}
-
 type keyMap struct {
 	Up        key.Binding
 	Down      key.Binding
@@ -257,13 +573,27 @@ type keyMap struct {
 	Help      key.Binding
 	Quit      key.Binding
 	Delete    key.Binding
-	Edit      key.Binding
-	Add       key.Binding
-	Reload    key.Binding
-	Sync      key.Binding
+	Archive   key.Binding
+	// ShowArchived switches between listing active and archived goals
+	ShowArchived key.Binding
+	Edit         key.Binding
+	Add          key.Binding
+	// AddSub adds a sub-goal to the focused goal, and Indent and Outdent
+	// move the focused goal a level down, under the goal above it, or up
+	AddSub  key.Binding
+	Indent  key.Binding
+	Outdent key.Binding
+	Reload  key.Binding
+	Sync    key.Binding
+	// WithSubGoals, KeepSubGoals and Cancel answer whether a goal's
+	// sub-goals are archived or deleted along with it
+	WithSubGoals key.Binding
+	KeepSubGoals key.Binding
+	Cancel       key.Binding
 }
 
-var keys = keyMap{
+// defaultKeys are the bindings used unless the user configures others
+var defaultKeys = keyMap{
 	Up: key.NewBinding(
 		key.WithKeys("up", "k"),
 		key.WithHelp("↑/k", "move up"),
@@ -285,13 +615,21 @@ var keys = keyMap{
 		key.WithHelp("?", "toggle help"),
 	),
 	Quit: key.NewBinding(
-		key.WithKeys("q", "esc", "ctrl+c"),
+		key.WithKeys("q", "ctrl+c"),
 		key.WithHelp("q", "quit"),
 	),
 	Delete: key.NewBinding(
 		key.WithKeys("d"),
 		key.WithHelp("d", "delete item"),
 	),
+	Archive: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "archive item"),
+	),
+	ShowArchived: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "show archived"),
+	),
 	Add: key.NewBinding(
 		key.WithKeys("a"),
 		key.WithHelp("a", "add item"),
@@ -300,6 +638,30 @@ var keys = keyMap{
 		key.WithKeys("e"),
 		key.WithHelp("e", "edit item"),
 	),
+	AddSub: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "add sub-goal"),
+	),
+	Indent: key.NewBinding(
+		key.WithKeys(">"),
+		key.WithHelp(">", "make sub-goal"),
+	),
+	Outdent: key.NewBinding(
+		key.WithKeys("<"),
+		key.WithHelp("<", "move up a level"),
+	),
+	WithSubGoals: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "sub-goals too"),
+	),
+	KeepSubGoals: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "keep sub-goals"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel"),
+	),
 	Reload: key.NewBinding(
 		key.WithKeys("r"),
 		key.WithHelp("r", "discard changes"),
@@ -320,8 +682,9 @@ func (k keyMap) ShortHelp() []key.Binding {
 // key.Map interface.
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Up, k.Down, k.ShiftDown, k.ShiftUp}, // first column
-		{k.Add, k.Edit},                        // second column
+		{k.Up, k.Down, k.ShiftDown, k.ShiftUp, k.Indent, k.Outdent},    // first column
+		{k.Add, k.AddSub, k.Edit, k.Archive, k.ShowArchived, k.Delete}, // second column
 		{k.Reload, k.Sync, k.Help, k.Quit},
+		{k.WithSubGoals, k.KeepSubGoals, k.Cancel},
 	}
 }
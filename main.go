@@ -1,15 +1,63 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
+	"github.com/benhsm/goalie/internal/cli"
+	"github.com/benhsm/goalie/internal/config"
+	"github.com/benhsm/goalie/internal/data"
 	"github.com/benhsm/goalie/internal/ui"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+	dbPath := flag.String("db", "", "path of the database file to use")
+	profile := flag.String("profile", "", "name of the profile whose database to use")
+	flag.Usage = func() {
+		cli.Usage(flag.CommandLine.Output())
+		fmt.Fprintln(flag.CommandLine.Output(), "\nOptions:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	configPath, err := config.Path()
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+
+	path, err := data.Path(*dbPath, *profile)
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+	store, err := data.NewStore(path)
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+	cfg.Day.Location, err = store.Location()
+	if err != nil {
+		fmt.Println("fatal:", err)
+		os.Exit(1)
+	}
+
+	if flag.NArg() > 0 {
+		if err := cli.Run(store, cfg, flag.Args(), os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "goalie:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if len(os.Getenv("TEA_DEBUG")) > 0 {
 		f, err := tea.LogToFile("debug.log", "debug")
 		if err != nil {
@@ -18,7 +66,12 @@ func main() {
 		}
 		defer f.Close()
 	}
-	p := tea.NewProgram(ui.New(), tea.WithAltScreen())
+	model := ui.New(store, cfg)
+	if problems := model.Keys.Problems(); len(problems) > 0 {
+		fmt.Println("fatal:", &config.Error{Path: configPath, Problems: problems})
+		os.Exit(1)
+	}
+	p := tea.NewProgram(model, tea.WithAltScreen())
 	if err := p.Start(); err != nil {
 		log.Fatal(err)
 	}